@@ -30,6 +30,18 @@ func CalculateAccuracy(accuracy AccuracyInterface, predictions *mat.Dense, targe
 		}
 	}
 
+	sum, samples := summarize(comparisons)
+	accuracy.AddAccumulated(sum, samples)
+
+	return float64(countTrue) / float64(count)
+}
+
+// returns values which CalculateAccuracy adds to accumulated ones, without changing the accumulator
+func CountCorrect(accuracy AccuracyInterface, predictions *mat.Dense, target *mat.Dense) (float64, int64) {
+	return summarize(accuracy.Compare(predictions, target))
+}
+
+func summarize(comparisons [][]bool) (float64, int64) {
 	sum := 0.0
 	for _, row := range comparisons {
 		for _, v := range row {
@@ -38,10 +50,7 @@ func CalculateAccuracy(accuracy AccuracyInterface, predictions *mat.Dense, targe
 			}
 		}
 	}
-
-	accuracy.AddAccumulated(sum, int64(len(comparisons)))
-
-	return float64(countTrue) / float64(count)
+	return sum, int64(len(comparisons))
 }
 
 func (accuracy *BaseAccuracy) AddAccumulated(accuracySum float64, count int64) {
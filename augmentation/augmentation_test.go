@@ -0,0 +1,118 @@
+package augmentation_test
+
+import (
+	"main/accuracy"
+	"main/activation"
+	"main/augmentation"
+	"main/layer"
+	"main/loss"
+	"main/model"
+	"main/optimizer"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// one channel 2x3 image
+var shape = layer.InputShape{Depths: 1, Height: 2, Width: 3}
+
+func apply(transform augmentation.Transform) []float64 {
+	sample := []float64{1, 2, 3, 4, 5, 6}
+	transform.Apply(sample, shape, rand.New(rand.NewSource(1)))
+	return sample
+}
+
+func TestTransforms(t *testing.T) {
+	cases := []struct {
+		transform augmentation.Transform
+		expected  []float64
+	}{
+		{&augmentation.HorizontalFlip{Probability: 1}, []float64{3, 2, 1, 6, 5, 4}},
+		{&augmentation.HorizontalFlip{Probability: 0}, []float64{1, 2, 3, 4, 5, 6}},
+		{&augmentation.VerticalFlip{Probability: 1}, []float64{4, 5, 6, 1, 2, 3}},
+		{&augmentation.Shift{MaxShift: 0}, []float64{1, 2, 3, 4, 5, 6}},
+		{&augmentation.Rotation{MaxDegrees: 0}, []float64{1, 2, 3, 4, 5, 6}},
+	}
+	for _, c := range cases {
+		if actual := apply(c.transform); !floats.EqualApprox(c.expected, actual, 1e-12) {
+			t.Errorf("%T: expected %v, got %v", c.transform, c.expected, actual)
+		}
+	}
+
+	brightness := apply(&augmentation.Brightness{MaxDelta: 0.5})
+	delta := brightness[0] - 1
+	if delta == 0 || delta < -0.5 || delta > 0.5 {
+		t.Fatalf("Unexpected brightness change: %v", delta)
+	}
+	for i, v := range brightness {
+		if math.Abs(v-float64(i+1)-delta) > 1e-12 {
+			t.Fatalf("Brightness has to change every value equally: %v", brightness)
+		}
+	}
+}
+
+func TestShiftFillsUncoveredPixels(t *testing.T) {
+	sample := []float64{1, 2, 3, 4, 5, 6}
+	random := rand.New(rand.NewSource(2))
+	for {
+		copy(sample, []float64{1, 2, 3, 4, 5, 6})
+		(&augmentation.Shift{MaxShift: 1, Fill: -1}).Apply(sample, shape, random)
+		if !floats.Equal(sample, []float64{1, 2, 3, 4, 5, 6}) {
+			break
+		}
+	}
+	if floats.Count(func(v float64) bool { return v == -1 }, sample) == 0 {
+		t.Fatalf("Shifted image has to contain fill values: %v", sample)
+	}
+}
+
+func TestAugmentationLayerRunsOnlyInTraining(t *testing.T) {
+	l := (&augmentation.AugmentationLayer{}).Initialization(shape, &augmentation.Noise{StdDev: 1})
+	inputs := mat.NewDense(2, 6, []float64{1, 2, 3, 4, 5, 6, 6, 5, 4, 3, 2, 1})
+
+	l.Forward(inputs, false)
+	if !mat.Equal(inputs, l.GetOutput()) {
+		t.Fatal("Augmentation has to pass inputs through during inference")
+	}
+
+	l.Forward(inputs, true)
+	if mat.Equal(inputs, l.GetOutput()) {
+		t.Fatal("Augmentation has to change inputs during training")
+	}
+	if inputs.At(0, 0) != 1 {
+		t.Fatal("Augmentation must not change original inputs")
+	}
+}
+
+func TestAugmentationLayerIsStored(t *testing.T) {
+	m := model.Model{}
+	m.Add((&augmentation.AugmentationLayer{}).Initialization(shape,
+		&augmentation.HorizontalFlip{Probability: 0.5},
+		&augmentation.Rotation{MaxDegrees: 10, Fill: -1},
+	))
+	m.Add((&layer.DenseLayer{}).Initialization(6, 2))
+	m.Add(&activation.SoftmaxActivation{})
+	o := optimizer.NewAdam()
+	m.Set(&loss.CategoricalCrossentropyLoss{}, &o, &accuracy.CategorialAccuracy{})
+	m.Finalize()
+
+	path := filepath.Join(t.TempDir(), "model.json")
+	provider := model.JSONModelDataProvider{}
+	if err := provider.Store(path, &m); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := provider.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, ok := loaded.Layers[0].(*augmentation.AugmentationLayer)
+	if !ok || !reflect.DeepEqual(l.Transforms, m.Layers[0].(*augmentation.AugmentationLayer).Transforms) || l.InputShape != shape {
+		t.Fatalf("Unexpected loaded layer: %#v", loaded.Layers[0])
+	}
+}
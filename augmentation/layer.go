@@ -0,0 +1,66 @@
+package augmentation
+
+import (
+	"main/layer"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+// AugmentationLayer applies Transforms to every sample during training and passes samples unchanged otherwise
+// it's meant to be the first layer of a model, so evaluation and prediction see original samples
+type AugmentationLayer struct {
+	InputShape layer.InputShape
+	Transforms []Transform
+
+	Output  mat.Dense
+	DInputs mat.Dense
+
+	source rand.Source
+}
+
+func (l *AugmentationLayer) Name() string {
+	return "Augmentation Layer"
+}
+
+func (l *AugmentationLayer) Initialization(shape layer.InputShape, transforms ...Transform) *AugmentationLayer {
+	l.InputShape = shape
+	l.Transforms = transforms
+	return l
+}
+
+func (l *AugmentationLayer) Forward(inputs *mat.Dense, isTraining bool) {
+	l.Output = *mat.DenseCopyOf(inputs)
+	if !isTraining {
+		return
+	}
+
+	if l.source == nil {
+		l.source = rand.NewSource(1)
+	}
+	random := rand.New(l.source)
+	rows, _ := l.Output.Dims()
+	for i := 0; i < rows; i++ {
+		sample := l.Output.RawRowView(i)
+		for _, transform := range l.Transforms {
+			transform.Apply(sample, l.InputShape, random)
+		}
+	}
+}
+
+// inputs aren't trainable, so gradients are passed unchanged
+func (l *AugmentationLayer) Backward(dvalues *mat.Dense) {
+	l.DInputs = *mat.DenseCopyOf(dvalues)
+}
+
+func (l *AugmentationLayer) SetRandomSource(source rand.Source) {
+	l.source = source
+}
+
+func (l *AugmentationLayer) GetOutput() *mat.Dense {
+	return &l.Output
+}
+
+func (l *AugmentationLayer) GetDInputs() *mat.Dense {
+	return &l.DInputs
+}
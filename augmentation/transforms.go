@@ -0,0 +1,139 @@
+package augmentation
+
+import (
+	"main/layer"
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// Transform randomly changes one sample in place
+// sample has layout of layer.InputShape: every depth is stored row by row
+type Transform interface {
+	Apply(sample []float64, shape layer.InputShape, random *rand.Rand)
+}
+
+// HorizontalFlip mirrors columns of the image with given probability
+type HorizontalFlip struct {
+	Probability float64 `json:"probability"`
+}
+
+func (t *HorizontalFlip) Apply(sample []float64, shape layer.InputShape, random *rand.Rand) {
+	if random.Float64() >= t.Probability {
+		return
+	}
+	for k := 0; k < shape.Depths; k++ {
+		for i := 0; i < shape.Height; i++ {
+			row := sample[(k*shape.Height+i)*shape.Width : (k*shape.Height+i+1)*shape.Width]
+			for l, r := 0, len(row)-1; l < r; l, r = l+1, r-1 {
+				row[l], row[r] = row[r], row[l]
+			}
+		}
+	}
+}
+
+// VerticalFlip mirrors rows of the image with given probability
+type VerticalFlip struct {
+	Probability float64 `json:"probability"`
+}
+
+func (t *VerticalFlip) Apply(sample []float64, shape layer.InputShape, random *rand.Rand) {
+	if random.Float64() >= t.Probability {
+		return
+	}
+	for k := 0; k < shape.Depths; k++ {
+		channel := sample[k*shape.Height*shape.Width : (k+1)*shape.Height*shape.Width]
+		for top, bottom := 0, shape.Height-1; top < bottom; top, bottom = top+1, bottom-1 {
+			for j := 0; j < shape.Width; j++ {
+				a, b := top*shape.Width+j, bottom*shape.Width+j
+				channel[a], channel[b] = channel[b], channel[a]
+			}
+		}
+	}
+}
+
+// Shift moves the image by up to MaxShift pixels along both axes, which is the same as a random crop of padded image
+// uncovered pixels are set to Fill
+type Shift struct {
+	MaxShift int     `json:"max_shift"`
+	Fill     float64 `json:"fill"`
+}
+
+func (t *Shift) Apply(sample []float64, shape layer.InputShape, random *rand.Rand) {
+	dy := random.Intn(2*t.MaxShift+1) - t.MaxShift
+	dx := random.Intn(2*t.MaxShift+1) - t.MaxShift
+	resample(sample, shape, t.Fill, func(i, j int) (float64, float64) {
+		return float64(i - dy), float64(j - dx)
+	})
+}
+
+// Rotation rotates the image around its center by up to MaxDegrees in both directions
+// pixels outside of the original image are set to Fill
+type Rotation struct {
+	MaxDegrees float64 `json:"max_degrees"`
+	Fill       float64 `json:"fill"`
+}
+
+func (t *Rotation) Apply(sample []float64, shape layer.InputShape, random *rand.Rand) {
+	angle := (random.Float64()*2 - 1) * t.MaxDegrees * math.Pi / 180
+	sin, cos := math.Sincos(angle)
+	centerI, centerJ := float64(shape.Height-1)/2, float64(shape.Width-1)/2
+
+	// every output pixel takes value of the input position rotated back
+	resample(sample, shape, t.Fill, func(i, j int) (float64, float64) {
+		y, x := float64(i)-centerI, float64(j)-centerJ
+		return centerI + y*cos - x*sin, centerJ + y*sin + x*cos
+	})
+}
+
+// Noise adds gaussian noise with standard deviation StdDev to every value
+type Noise struct {
+	StdDev float64 `json:"std_dev"`
+}
+
+func (t *Noise) Apply(sample []float64, shape layer.InputShape, random *rand.Rand) {
+	for i := range sample {
+		sample[i] += random.NormFloat64() * t.StdDev
+	}
+}
+
+// Brightness adds the same random value from -MaxDelta...MaxDelta to every value of the image
+type Brightness struct {
+	MaxDelta float64 `json:"max_delta"`
+}
+
+func (t *Brightness) Apply(sample []float64, shape layer.InputShape, random *rand.Rand) {
+	delta := (random.Float64()*2 - 1) * t.MaxDelta
+	for i := range sample {
+		sample[i] += delta
+	}
+}
+
+// replaces every pixel (i, j) by bilinear interpolation of the original image at position returned by source
+func resample(sample []float64, shape layer.InputShape, fill float64, source func(i, j int) (float64, float64)) {
+	size := shape.Height * shape.Width
+	original := make([]float64, size)
+	for k := 0; k < shape.Depths; k++ {
+		channel := sample[k*size : (k+1)*size]
+		copy(original, channel)
+
+		at := func(i, j int) float64 {
+			if i < 0 || j < 0 || i >= shape.Height || j >= shape.Width {
+				return fill
+			}
+			return original[i*shape.Width+j]
+		}
+
+		for i := 0; i < shape.Height; i++ {
+			for j := 0; j < shape.Width; j++ {
+				y, x := source(i, j)
+				i0, j0 := int(math.Floor(y)), int(math.Floor(x))
+				dy, dx := y-float64(i0), x-float64(j0)
+				channel[i*shape.Width+j] = at(i0, j0)*(1-dy)*(1-dx) +
+					at(i0, j0+1)*(1-dy)*dx +
+					at(i0+1, j0)*dy*(1-dx) +
+					at(i0+1, j0+1)*dy*dx
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+// converts stored models between JSON and binary formats; format is chosen by file extension
+// go run ./cmd/convert -in assets/fashion-dense.json -out assets/fashion-dense.bin
+
+import (
+	"flag"
+	"log"
+	"main/model"
+)
+
+func main() {
+	in := flag.String("in", "", "path of the stored model")
+	out := flag.String("out", "", "path of the converted model")
+	asFloat32 := flag.Bool("float32", false, "store tensors of binary model as float32")
+	flag.Parse()
+
+	if *in == "" || *out == "" {
+		flag.Usage()
+		log.Fatal("both -in and -out are required")
+	}
+
+	dst := model.ProviderForPath(*out)
+	if binaryProvider, ok := dst.(*model.BinaryModelDataProvider); ok {
+		binaryProvider.Float32 = *asFloat32
+	}
+
+	err := model.ConvertModel(*in, model.ProviderForPath(*in), *out, dst)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,106 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CSVSource reads rows of numeric CSV file; only positions of rows are kept in memory
+// fields can't contain line breaks
+type CSVSource struct {
+	file *os.File
+	rows []csvRow
+	// indexes of columns used as targets; other columns are features
+	targetColumns []int
+}
+
+type csvRow struct {
+	offset int64
+	length int
+}
+
+func NewCSVSource(path string, header bool, targetColumns ...int) (*CSVSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	source := &CSVSource{file: file, targetColumns: targetColumns}
+
+	reader := bufio.NewReader(file)
+	offset := int64(0)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			source.rows = append(source.rows, csvRow{offset: offset, length: len(line)})
+		}
+		offset += int64(len(line))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	if header && len(source.rows) > 0 {
+		source.rows = source.rows[1:]
+	}
+	if len(source.rows) == 0 {
+		file.Close()
+		return nil, errors.New("Empty dataset")
+	}
+	return source, nil
+}
+
+func (source *CSVSource) Len() int {
+	return len(source.rows)
+}
+
+func (source *CSVSource) Sample(i int) ([]float64, []float64, error) {
+	row := source.rows[i]
+	line := make([]byte, row.length)
+	_, err := source.file.ReadAt(line, row.offset)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+
+	fields, err := csv.NewReader(bytes.NewReader(line)).Read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	x := make([]float64, 0, len(fields))
+	values := make([]float64, len(fields))
+	for column, field := range fields {
+		values[column], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("row %v, column %v: %v", i, column, err)
+		}
+		if !slices.Contains(source.targetColumns, column) {
+			x = append(x, values[column])
+		}
+	}
+
+	// targets follow order of targetColumns rather than order of columns in the file
+	y := make([]float64, len(source.targetColumns))
+	for k, column := range source.targetColumns {
+		if column < 0 || column >= len(values) {
+			return nil, nil, fmt.Errorf("row %v: missing target column %v", i, column)
+		}
+		y[k] = values[column]
+	}
+	return x, y, nil
+}
+
+func (source *CSVSource) Close() error {
+	return source.file.Close()
+}
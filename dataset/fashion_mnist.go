@@ -44,7 +44,7 @@ func (f *FashionMNISTDataset) loadDataset(datasetPath string) (*mat.Dense, *mat.
 		if err != nil {
 			return err
 		}
-		imageData, err := f.loadImage(rootPath)
+		imageData, err := loadImage(rootPath)
 		if err != nil {
 			return err
 		}
@@ -82,7 +82,16 @@ func (f *FashionMNISTDataset) loadDataset(datasetPath string) (*mat.Dense, *mat.
 	return resultData, resultLabels, nil
 }
 
-func (f *FashionMNISTDataset) loadImage(path string) ([]float64, error) {
+// streams training images from disk instead of loading all of them into memory
+func (f *FashionMNISTDataset) TrainingSource() (*ImageFolderSource, error) {
+	return NewImageFolderSource(trainingPath)
+}
+
+func (f *FashionMNISTDataset) TestingSource() (*ImageFolderSource, error) {
+	return NewImageFolderSource(testingPath)
+}
+
+func loadImage(path string) ([]float64, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
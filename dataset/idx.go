@@ -0,0 +1,169 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const idxUnsignedByte = 0x08
+
+// sizes of dimensions come from the file, so values are limited before allocating them
+// the largest canonical file (60000 images of 28x28) is about 47MB
+const maxIDXSize = 1 << 30
+
+// IDXDataset reads MNIST, Fashion-MNIST and KMNIST from the canonical IDX files stored in Dir
+// every file can be gzipped, with or without .gz extension
+type IDXDataset struct {
+	Dir string
+}
+
+func (d *IDXDataset) TrainingDataset() (*mat.Dense, *mat.Dense, error) {
+	return d.load("train-images-idx3-ubyte", "train-labels-idx1-ubyte")
+}
+
+func (d *IDXDataset) TestingDataset() (*mat.Dense, *mat.Dense, error) {
+	return d.load("t10k-images-idx3-ubyte", "t10k-labels-idx1-ubyte")
+}
+
+func (d *IDXDataset) load(images, labels string) (*mat.Dense, *mat.Dense, error) {
+	imagesPath, err := findIDXFile(filepath.Join(d.Dir, images))
+	if err != nil {
+		return nil, nil, err
+	}
+	labelsPath, err := findIDXFile(filepath.Join(d.Dir, labels))
+	if err != nil {
+		return nil, nil, err
+	}
+	return LoadIDX(imagesPath, labelsPath)
+}
+
+// prefers uncompressed file
+func findIDXFile(path string) (string, error) {
+	for _, candidate := range []string{path, path + ".gz"} {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%v not found", path)
+}
+
+// LoadIDX reads images and their labels; images are normalized to -1.0...1.0
+// the same way as PNG images of FashionMNISTDataset, so models trained on either of them are interchangeable
+func LoadIDX(imagesPath, labelsPath string) (*mat.Dense, *mat.Dense, error) {
+	imageDims, pixels, err := readIDXFile(imagesPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	labelDims, labels, err := readIDXFile(labelsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(imageDims) != 3 {
+		return nil, nil, fmt.Errorf("%v doesn't contain images", imagesPath)
+	}
+	if len(labelDims) != 1 {
+		return nil, nil, fmt.Errorf("%v doesn't contain labels", labelsPath)
+	}
+	count, height, width := imageDims[0], imageDims[1], imageDims[2]
+	if count != labelDims[0] {
+		return nil, nil, errors.New("inconsistency between images number and labels")
+	}
+	if count == 0 {
+		return nil, nil, errors.New("Empty dataset")
+	}
+
+	size := height * width
+	x := mat.NewDense(count, size, nil)
+	y := mat.NewDense(count, 1, nil)
+	for i := 0; i < count; i++ {
+		image := pixels[i*size : (i+1)*size]
+		row := x.RawRowView(i)
+		// IDX stores images row by row, but images loaded from PNG are stored column by column
+		for r := 0; r < height; r++ {
+			for c := 0; c < width; c++ {
+				row[c*height+r] = (float64(image[r*width+c]) - 127.5) / 127.5
+			}
+		}
+		y.Set(i, 0, float64(labels[i]))
+	}
+	return x, y, nil
+}
+
+func readIDXFile(path string) ([]int, []byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	dims, data, err := ReadIDX(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: %v", path, err)
+	}
+	return dims, data, nil
+}
+
+// ReadIDX reads IDX file of unsigned bytes, gzipped or not
+// returns sizes of dimensions and values in row-major order
+func ReadIDX(r io.Reader) ([]int, []byte, error) {
+	reader := bufio.NewReader(r)
+	gzipMagic, err := reader.Peek(2)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bytes.Equal(gzipMagic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer gz.Close()
+		reader = bufio.NewReader(gz)
+	}
+
+	magic := make([]byte, 4)
+	_, err = io.ReadFull(reader, magic)
+	if err != nil {
+		return nil, nil, err
+	}
+	if magic[0] != 0 || magic[1] != 0 {
+		return nil, nil, errors.New("not an IDX file")
+	}
+	if magic[2] != idxUnsignedByte {
+		return nil, nil, fmt.Errorf("unsupported IDX data type: %#x", magic[2])
+	}
+
+	dims := make([]int, magic[3])
+	size := 1
+	for i := range dims {
+		var dim uint32
+		err = binary.Read(reader, binary.BigEndian, &dim)
+		if err != nil {
+			return nil, nil, err
+		}
+		dims[i] = int(dim)
+		if dims[i] != 0 && size > maxIDXSize/dims[i] {
+			return nil, nil, fmt.Errorf("IDX file is too large: %v", dims[:i+1])
+		}
+		size *= dims[i]
+	}
+
+	// grows with values actually read, so truncated files don't allocate the declared size
+	data, err := io.ReadAll(io.LimitReader(reader, int64(size)))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) != size {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	return dims, data, nil
+}
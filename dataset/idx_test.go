@@ -0,0 +1,101 @@
+package dataset_test
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"main/dataset"
+	"main/utils"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func writeIDX(t *testing.T, path string, compress bool, dims []int, data []byte) {
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var w interface {
+		Write([]byte) (int, error)
+	} = file
+	if compress {
+		gz := gzip.NewWriter(file)
+		defer gz.Close()
+		w = gz
+	}
+
+	w.Write([]byte{0, 0, 0x08, byte(len(dims))})
+	for _, dim := range dims {
+		binary.Write(w, binary.BigEndian, uint32(dim))
+	}
+	w.Write(data)
+}
+
+func TestIDXDatasetMatchesImageNormalization(t *testing.T) {
+	// NormalizeGrascaleImageData supports only square images
+	const count, height, width = 2, 3, 3
+	pixels := make([]byte, count*height*width)
+	for i := range pixels {
+		pixels[i] = byte(i * 10)
+	}
+
+	dir := t.TempDir()
+	writeIDX(t, filepath.Join(dir, "t10k-images-idx3-ubyte.gz"), true, []int{count, height, width}, pixels)
+	writeIDX(t, filepath.Join(dir, "t10k-labels-idx1-ubyte"), false, []int{count}, []byte{7, 3})
+
+	ds := dataset.IDXDataset{Dir: dir}
+	x, y, err := ds.TestingDataset()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < count; i++ {
+		img := image.NewGray(image.Rect(0, 0, width, height))
+		for r := 0; r < height; r++ {
+			for c := 0; c < width; c++ {
+				img.SetGray(c, r, color.Gray{Y: pixels[i*height*width+r*width+c]})
+			}
+		}
+		expected, err := utils.NormalizeGrascaleImageData(img, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !floats.Equal(expected, x.RawRowView(i)) {
+			t.Fatalf("Image %v differs from PNG normalization: %v != %v", i, x.RawRowView(i), expected)
+		}
+	}
+	if y.At(0, 0) != 7 || y.At(1, 0) != 3 {
+		t.Fatalf("Unexpected labels: %v", y.RawMatrix().Data)
+	}
+}
+
+func TestReadIDXRejectsInvalidSizes(t *testing.T) {
+	cases := []struct {
+		name string
+		dims []int
+		data []byte
+	}{
+		{"too large", []int{1 << 20, 1 << 20, 1 << 20}, nil},
+		{"truncated", []int{1 << 30}, []byte{1, 2, 3}},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(t.TempDir(), "data-idx")
+		writeIDX(t, path, false, c.dims, c.data)
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = dataset.ReadIDX(file)
+		file.Close()
+		if err == nil {
+			t.Errorf("%v: missing error", c.name)
+		}
+	}
+}
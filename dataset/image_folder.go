@@ -0,0 +1,63 @@
+package dataset
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ImageFolderSource reads grayscale PNG images from directories named by class index
+// e.g. root/0/image.png, root/1/image.png like unzipped Fashion MNIST
+// other files, e.g. .DS_Store, and files directly in root are skipped
+// only paths are kept in memory, images are decoded when samples are requested
+type ImageFolderSource struct {
+	paths  []string
+	labels []float64
+}
+
+func NewImageFolderSource(root string) (*ImageFolderSource, error) {
+	source := &ImageFolderSource{}
+
+	// WalkDir visits files in lexical order, so samples have the same indexes every time
+	err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.ToLower(filepath.Ext(filePath)) != ".png" {
+			return nil
+		}
+		dir := filepath.Dir(filePath)
+		if dir == filepath.Clean(root) {
+			return nil
+		}
+		label, err := strconv.Atoi(filepath.Base(dir))
+		if err != nil {
+			return err
+		}
+
+		source.paths = append(source.paths, filePath)
+		source.labels = append(source.labels, float64(label))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(source.paths) == 0 {
+		return nil, errors.New("Empty dataset")
+	}
+	return source, nil
+}
+
+func (source *ImageFolderSource) Len() int {
+	return len(source.paths)
+}
+
+func (source *ImageFolderSource) Sample(i int) ([]float64, []float64, error) {
+	x, err := loadImage(source.paths[i])
+	if err != nil {
+		return nil, nil, err
+	}
+	return x, []float64{source.labels[i]}, nil
+}
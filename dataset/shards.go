@@ -0,0 +1,179 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"main/model"
+	"math"
+	"os"
+	"sort"
+)
+
+// Shard file layout, all numbers are little-endian:
+//
+//	magic    [4]byte "NNSH"
+//	features uint32
+//	targets  uint32
+//	samples  uint64
+//	samples * (features + targets) float32 values; features of a sample are followed by its targets
+const (
+	shardMagic      = "NNSH"
+	shardHeaderSize = 4 + 4 + 4 + 8
+)
+
+// WriteShard stores data in the shard format read by ShardSource
+// large datasets can be split into several shards which are read as one source
+func WriteShard(path string, data model.ModelData) error {
+	samples, features := data.X.Dims()
+	targets := 0
+	if !data.Y.IsEmpty() {
+		_, targets = data.Y.Dims()
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	header := append([]byte(shardMagic), make([]byte, shardHeaderSize-len(shardMagic))...)
+	binary.LittleEndian.PutUint32(header[4:], uint32(features))
+	binary.LittleEndian.PutUint32(header[8:], uint32(targets))
+	binary.LittleEndian.PutUint64(header[12:], uint64(samples))
+	_, err = w.Write(header)
+	if err != nil {
+		return err
+	}
+
+	record := make([]byte, 4*(features+targets))
+	for i := 0; i < samples; i++ {
+		for j, v := range data.X.RawRowView(i) {
+			binary.LittleEndian.PutUint32(record[4*j:], math.Float32bits(float32(v)))
+		}
+		if targets > 0 {
+			for j, v := range data.Y.RawRowView(i) {
+				binary.LittleEndian.PutUint32(record[4*(features+j):], math.Float32bits(float32(v)))
+			}
+		}
+		_, err = w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// ShardSource reads samples of one or more shard files written by WriteShard
+type ShardSource struct {
+	shards []shard
+}
+
+type shard struct {
+	file     *os.File
+	features int
+	targets  int
+	// index of the first sample of the shard within the source
+	first   int
+	samples int
+}
+
+func NewShardSource(paths ...string) (*ShardSource, error) {
+	source := &ShardSource{}
+	count := 0
+	for _, path := range paths {
+		s, err := openShard(path)
+		if err != nil {
+			source.Close()
+			return nil, err
+		}
+		if len(source.shards) > 0 && (s.features != source.shards[0].features || s.targets != source.shards[0].targets) {
+			s.file.Close()
+			source.Close()
+			return nil, fmt.Errorf("shard %v has different shape", path)
+		}
+		s.first = count
+		count += s.samples
+		source.shards = append(source.shards, s)
+	}
+	if count == 0 {
+		source.Close()
+		return nil, errors.New("Empty dataset")
+	}
+	return source, nil
+}
+
+func openShard(path string) (shard, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return shard{}, err
+	}
+
+	header := make([]byte, shardHeaderSize)
+	_, err = io.ReadFull(file, header)
+	if err != nil || string(header[:4]) != shardMagic {
+		file.Close()
+		return shard{}, fmt.Errorf("%v is not a shard file", path)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return shard{}, err
+	}
+	features := uint64(binary.LittleEndian.Uint32(header[4:]))
+	targets := uint64(binary.LittleEndian.Uint32(header[8:]))
+	samples := binary.LittleEndian.Uint64(header[12:])
+	// truncated shards are found here rather than midway through an epoch
+	// sizes are divided instead of multiplied, so large values from the header can't overflow
+	recordSize := 4 * (features + targets)
+	dataSize := uint64(info.Size()) - shardHeaderSize
+	if recordSize == 0 || dataSize%recordSize != 0 || dataSize/recordSize != samples {
+		file.Close()
+		return shard{}, fmt.Errorf("%v: size %v doesn't match %v samples of %v values", path, info.Size(), samples, features+targets)
+	}
+
+	return shard{
+		file:     file,
+		features: int(features),
+		targets:  int(targets),
+		samples:  int(samples),
+	}, nil
+}
+
+func (source *ShardSource) Len() int {
+	last := source.shards[len(source.shards)-1]
+	return last.first + last.samples
+}
+
+func (source *ShardSource) Sample(i int) ([]float64, []float64, error) {
+	// the last shard which starts before i
+	index := sort.Search(len(source.shards), func(k int) bool { return source.shards[k].first > i }) - 1
+	s := source.shards[index]
+
+	size := s.features + s.targets
+	record := make([]byte, 4*size)
+	_, err := s.file.ReadAt(record, int64(shardHeaderSize+4*size*(i-s.first)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]float64, size)
+	for j := range values {
+		values[j] = float64(math.Float32frombits(binary.LittleEndian.Uint32(record[4*j:])))
+	}
+	return values[:s.features], values[s.features:], nil
+}
+
+func (source *ShardSource) Close() error {
+	var result error
+	for _, s := range source.shards {
+		if err := s.file.Close(); err != nil {
+			result = err
+		}
+	}
+	return result
+}
@@ -0,0 +1,172 @@
+package dataset
+
+import (
+	"fmt"
+	"main/model"
+	"sync"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// SampleSource reads samples one by one, e.g. from files which don't fit in memory
+// Sample can be called from several goroutines at once
+type SampleSource interface {
+	// number of samples
+	Len() int
+	// returns features and targets of sample i
+	Sample(i int) (x []float64, y []float64, err error)
+}
+
+// StreamLoader reads batches of samples from SampleSource when they are needed
+// it implements model.DataLoader, so it can be passed to Model.TrainWithLoader
+type StreamLoader struct {
+	model.BatchSampler
+	Source SampleSource
+
+	// number of batches read in background while the model processes the current one
+	// 0 reads every batch in Next
+	Prefetch int
+	// number of goroutines reading samples of one batch; 0 means one
+	Workers int
+
+	batches  [][]int
+	position int
+	err      error
+
+	// used only with prefetching
+	prefetched chan streamBatch
+	stop       chan struct{}
+	done       sync.WaitGroup
+}
+
+type streamBatch struct {
+	data model.ModelData
+	err  error
+}
+
+func NewStreamLoader(source SampleSource, batchSize int) *StreamLoader {
+	loader := &StreamLoader{Source: source}
+	loader.BatchSize = batchSize
+	return loader
+}
+
+func (loader *StreamLoader) Begin(epoch int) {
+	loader.stopPrefetching()
+
+	loader.batches = loader.Batches(loader.Source.Len(), epoch)
+	loader.position = 0
+	loader.err = nil
+
+	if loader.Prefetch > 0 {
+		loader.prefetched = make(chan streamBatch, loader.Prefetch)
+		loader.stop = make(chan struct{})
+		loader.done.Add(1)
+		go loader.prefetch(loader.batches, loader.prefetched, loader.stop)
+	}
+}
+
+func (loader *StreamLoader) Next() (model.ModelData, bool) {
+	if loader.err != nil || loader.position >= len(loader.batches) {
+		return model.ModelData{}, false
+	}
+
+	var batch streamBatch
+	if loader.prefetched != nil {
+		batch = <-loader.prefetched
+	} else {
+		batch.data, batch.err = loader.read(loader.batches[loader.position])
+	}
+	loader.position += 1
+
+	if batch.err != nil {
+		loader.err = batch.err
+		loader.stopPrefetching()
+		return model.ModelData{}, false
+	}
+	return batch.data, true
+}
+
+func (loader *StreamLoader) Steps() int {
+	return loader.BatchSampler.Steps(loader.Source.Len())
+}
+
+func (loader *StreamLoader) Err() error {
+	return loader.err
+}
+
+// reads batches in order until all of them are read or stop is closed
+func (loader *StreamLoader) prefetch(batches [][]int, prefetched chan<- streamBatch, stop <-chan struct{}) {
+	defer loader.done.Done()
+
+	for _, indexes := range batches {
+		data, err := loader.read(indexes)
+		select {
+		case prefetched <- streamBatch{data: data, err: err}:
+		case <-stop:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (loader *StreamLoader) stopPrefetching() {
+	if loader.stop == nil {
+		return
+	}
+	close(loader.stop)
+	loader.done.Wait()
+	loader.stop = nil
+	loader.prefetched = nil
+}
+
+// reads samples of one batch using Workers goroutines
+func (loader *StreamLoader) read(indexes []int) (model.ModelData, error) {
+	xs := make([][]float64, len(indexes))
+	ys := make([][]float64, len(indexes))
+	errs := make([]error, len(indexes))
+
+	workers := max(1, min(loader.Workers, len(indexes)))
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(indexes); i += workers {
+				xs[i], ys[i], errs[i] = loader.Source.Sample(indexes[i])
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return model.ModelData{}, err
+		}
+	}
+	x, err := stackRows(xs)
+	if err != nil {
+		return model.ModelData{}, err
+	}
+	y, err := stackRows(ys)
+	if err != nil {
+		return model.ModelData{}, err
+	}
+	return model.ModelData{X: x, Y: y}, nil
+}
+
+func stackRows(rows [][]float64) (mat.Dense, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return mat.Dense{}, nil
+	}
+	cols := len(rows[0])
+	result := mat.NewDense(len(rows), cols, nil)
+	for i, row := range rows {
+		if len(row) != cols {
+			return mat.Dense{}, fmt.Errorf("sample has %v values instead of %v", len(row), cols)
+		}
+		result.SetRow(i, row)
+	}
+	return *result, nil
+}
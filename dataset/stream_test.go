@@ -0,0 +1,243 @@
+package dataset_test
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"main/dataset"
+	"main/model"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// sample i has features (i, -i) and target i
+type indexSource struct {
+	samples int
+	failAt  int
+}
+
+func (s indexSource) Len() int {
+	return s.samples
+}
+
+func (s indexSource) Sample(i int) ([]float64, []float64, error) {
+	if i == s.failAt {
+		return nil, nil, errors.New("broken sample")
+	}
+	return []float64{float64(i), -float64(i)}, []float64{float64(i)}, nil
+}
+
+func readEpoch(t *testing.T, loader model.DataLoader, epoch int) []model.ModelData {
+	loader.Begin(epoch)
+	batches := []model.ModelData{}
+	for {
+		batch, ok := loader.Next()
+		if !ok {
+			break
+		}
+		batches = append(batches, batch)
+	}
+	if err := loader.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return batches
+}
+
+func TestStreamLoaderPrefetch(t *testing.T) {
+	source := indexSource{samples: 10, failAt: -1}
+
+	plain := dataset.NewStreamLoader(source, 3)
+	plain.Shuffle = true
+
+	prefetching := dataset.NewStreamLoader(source, 3)
+	prefetching.Shuffle = true
+	prefetching.Prefetch = 2
+	prefetching.Workers = 4
+
+	for epoch := 0; epoch < 2; epoch++ {
+		expected := readEpoch(t, plain, epoch)
+		actual := readEpoch(t, prefetching, epoch)
+		if len(expected) != 4 || len(actual) != len(expected) {
+			t.Fatalf("Unexpected number of batches: %v and %v", len(expected), len(actual))
+		}
+		for i := range expected {
+			if !mat.Equal(&expected[i].X, &actual[i].X) || !mat.Equal(&expected[i].Y, &actual[i].Y) {
+				t.Fatalf("Batch %v of epoch %v differs with prefetching", i, epoch)
+			}
+		}
+	}
+
+	// an epoch can be abandoned before all prefetched batches are read
+	prefetching.Begin(2)
+	prefetching.Next()
+	if batches := readEpoch(t, prefetching, 3); len(batches) != 4 {
+		t.Fatalf("Unexpected number of batches after restart: %v", len(batches))
+	}
+}
+
+func TestStreamLoaderError(t *testing.T) {
+	for _, prefetch := range []int{0, 2} {
+		loader := dataset.NewStreamLoader(indexSource{samples: 10, failAt: 7}, 3)
+		loader.Prefetch = prefetch
+		loader.Begin(0)
+
+		batches := 0
+		for {
+			if _, ok := loader.Next(); !ok {
+				break
+			}
+			batches += 1
+		}
+		if batches != 2 || loader.Err() == nil {
+			t.Fatalf("Reading has to stop at the broken sample, prefetch %v: %v batches, error %v", prefetch, batches, loader.Err())
+		}
+	}
+}
+
+func TestShardSource(t *testing.T) {
+	dir := t.TempDir()
+	first := model.ModelData{
+		X: *mat.NewDense(2, 2, []float64{0, 0.5, 1, 1.5}),
+		Y: *mat.NewDense(2, 1, []float64{0, 1}),
+	}
+	second := model.ModelData{
+		X: *mat.NewDense(1, 2, []float64{2, 2.5}),
+		Y: *mat.NewDense(1, 1, []float64{2}),
+	}
+	paths := []string{filepath.Join(dir, "0.shard"), filepath.Join(dir, "1.shard")}
+	if err := dataset.WriteShard(paths[0], first); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataset.WriteShard(paths[1], second); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := dataset.NewShardSource(paths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+
+	batches := readEpoch(t, dataset.NewStreamLoader(source, 0), 0)
+	expectedX := mat.NewDense(3, 2, []float64{0, 0.5, 1, 1.5, 2, 2.5})
+	expectedY := mat.NewDense(3, 1, []float64{0, 1, 2})
+	if len(batches) != 1 || !mat.Equal(expectedX, &batches[0].X) || !mat.Equal(expectedY, &batches[0].Y) {
+		t.Fatalf("Unexpected samples read from shards: %v", batches)
+	}
+}
+
+func TestCSVSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	content := "a,label,b\n1,0,2\n\n3,1,4\n5,0,6"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := dataset.NewCSVSource(path, true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+
+	if source.Len() != 3 {
+		t.Fatalf("Expected 3 samples, got %v", source.Len())
+	}
+	batches := readEpoch(t, dataset.NewStreamLoader(source, 2), 0)
+	expectedX := mat.NewDense(1, 2, []float64{5, 6})
+	expectedY := mat.NewDense(1, 1, []float64{0})
+	if len(batches) != 2 || !mat.Equal(expectedX, &batches[1].X) || !mat.Equal(expectedY, &batches[1].Y) {
+		t.Fatalf("Unexpected samples read from CSV: %v", batches)
+	}
+}
+
+func TestCSVSourceTargetOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(path, []byte("1,2,3,4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := dataset.NewCSVSource(path, false, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+
+	x, y, err := source.Sample(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(x, []float64{2, 3}) || !slices.Equal(y, []float64{4, 1}) {
+		t.Fatalf("Unexpected sample: %v, %v", x, y)
+	}
+}
+
+func TestImageFolderSourceSkipsOtherFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]bool{
+		"0/a.png":     true,
+		"1/b.PNG":     true,
+		"1/.DS_Store": false,
+		"notes.png":   false,
+		"README.txt":  false,
+	}
+	for name, isImage := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isImage {
+			err = png.Encode(file, image.NewGray(image.Rect(0, 0, 2, 2)))
+		}
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source, err := dataset.NewImageFolderSource(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source.Len() != 2 {
+		t.Fatalf("Expected 2 images, got %v", source.Len())
+	}
+	for i, label := range []float64{0, 1} {
+		_, y, err := source.Sample(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if y[0] != label {
+			t.Fatalf("Unexpected label of sample %v: %v", i, y)
+		}
+	}
+}
+
+func TestTruncatedShard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "0.shard")
+	data := model.ModelData{
+		X: *mat.NewDense(2, 2, []float64{0, 0.5, 1, 1.5}),
+		Y: *mat.NewDense(2, 1, []float64{0, 1}),
+	}
+	if err := dataset.WriteShard(path, data); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-4); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := dataset.NewShardSource(path); err == nil {
+		t.Fatal("Expected error for truncated shard")
+	}
+}
@@ -0,0 +1,286 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"main/model"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type ColumnEncoding int
+
+const (
+	// value is parsed as a number
+	NumericColumn ColumnEncoding = iota
+	// every category gets its own 0/1 column
+	OneHotColumn
+	// category is replaced by its index, e.g. class of categorical crossentropy target
+	LabelColumn
+)
+
+// TabularColumn describes how values of one CSV column are converted into numbers
+type TabularColumn struct {
+	// name from the header; without header columns are named by their index: "0", "1", ...
+	Name     string
+	Encoding ColumnEncoding
+}
+
+// TabularCSV loads CSV file with one sample per row into model.ModelData
+//
+// categories and values which replace missing ones are collected by the first Load and stored in Categories and Fill,
+// so validation and test files loaded later are encoded the same way as the training one
+type TabularCSV struct {
+	Header bool
+	// field delimiter; 0 means ','
+	Comma rune
+
+	// feature columns in order of model inputs; empty means all columns except Target as numbers
+	Features []TabularColumn
+	// empty name means file without targets, e.g. samples for Model.Predict
+	Target TabularColumn
+
+	// values treated as missing in addition to empty fields, e.g. "NA" or "?"
+	MissingValues []string
+	// rows with missing values are skipped; otherwise missing numbers are replaced by mean of the column
+	// and missing categories by the most frequent one
+	DropMissing bool
+
+	// sorted categories of every categorical column by name
+	Categories map[string][]string
+	// values used instead of missing ones by column name
+	Fill map[string]float64
+}
+
+// Load reads file and encodes features into X and target into Y
+func (t *TabularCSV) Load(path string) (model.ModelData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return model.ModelData{}, err
+	}
+	defer file.Close()
+	return t.Read(file)
+}
+
+func (t *TabularCSV) Read(r io.Reader) (model.ModelData, error) {
+	reader := csv.NewReader(r)
+	if t.Comma != 0 {
+		reader.Comma = t.Comma
+	}
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return model.ModelData{}, err
+	}
+	if len(records) == 0 {
+		return model.ModelData{}, errors.New("Empty dataset")
+	}
+
+	var names []string
+	if t.Header {
+		names = records[0]
+		records = records[1:]
+	} else {
+		for i := range records[0] {
+			names = append(names, strconv.Itoa(i))
+		}
+	}
+
+	features := t.Features
+	if len(features) == 0 {
+		for _, name := range names {
+			if name != t.Target.Name {
+				features = append(features, TabularColumn{Name: name})
+			}
+		}
+	}
+
+	selected := slices.Clone(features)
+	if t.Target.Name != "" {
+		selected = append(selected, t.Target)
+	}
+	columns := make([]tabularColumn, 0, len(selected))
+	for _, column := range selected {
+		index := slices.Index(names, column.Name)
+		if index < 0 {
+			return model.ModelData{}, fmt.Errorf("column %v not found", column.Name)
+		}
+		columns = append(columns, tabularColumn{TabularColumn: column, index: index})
+	}
+
+	if t.DropMissing {
+		records = slices.DeleteFunc(records, func(record []string) bool {
+			return slices.ContainsFunc(columns, func(column tabularColumn) bool { return t.missing(record[column.index]) })
+		})
+		if len(records) == 0 {
+			return model.ModelData{}, errors.New("Empty dataset")
+		}
+	}
+
+	if t.Categories == nil {
+		t.Categories = map[string][]string{}
+	}
+	if t.Fill == nil {
+		t.Fill = map[string]float64{}
+	}
+
+	encoded := make([][][]float64, len(columns))
+	for i, column := range columns {
+		values := make([]string, len(records))
+		for j, record := range records {
+			values[j] = strings.TrimSpace(record[column.index])
+		}
+		encoded[i], err = t.encode(column.TabularColumn, values)
+		if err != nil {
+			return model.ModelData{}, err
+		}
+	}
+
+	return model.ModelData{
+		X: joinColumns(encoded[:len(features)]),
+		Y: joinColumns(encoded[len(features):]),
+	}, nil
+}
+
+type tabularColumn struct {
+	TabularColumn
+	index int
+}
+
+func (t *TabularCSV) missing(value string) bool {
+	value = strings.TrimSpace(value)
+	return value == "" || slices.Contains(t.MissingValues, value)
+}
+
+// returns encoded values of every row
+func (t *TabularCSV) encode(column TabularColumn, values []string) ([][]float64, error) {
+	if column.Encoding == NumericColumn {
+		return t.encodeNumbers(column.Name, values)
+	}
+
+	categories, ok := t.Categories[column.Name]
+	if !ok {
+		for _, value := range values {
+			if !t.missing(value) && !slices.Contains(categories, value) {
+				categories = append(categories, value)
+			}
+		}
+		slices.Sort(categories)
+		t.Categories[column.Name] = categories
+	}
+	if len(categories) == 0 {
+		return nil, fmt.Errorf("column %v doesn't have categories", column.Name)
+	}
+
+	indexes := make([]float64, len(values))
+	for i, value := range values {
+		if t.missing(value) {
+			indexes[i] = -1
+			continue
+		}
+		index := slices.Index(categories, value)
+		if index < 0 {
+			return nil, fmt.Errorf("column %v: unknown category %q", column.Name, value)
+		}
+		indexes[i] = float64(index)
+	}
+
+	fill, ok := t.Fill[column.Name]
+	if !ok {
+		fill = mostFrequent(indexes, len(categories))
+		t.Fill[column.Name] = fill
+	}
+
+	result := make([][]float64, len(values))
+	for i, index := range indexes {
+		if index < 0 {
+			index = fill
+		}
+		if column.Encoding == OneHotColumn {
+			result[i] = make([]float64, len(categories))
+			result[i][int(index)] = 1
+		} else {
+			result[i] = []float64{index}
+		}
+	}
+	return result, nil
+}
+
+func (t *TabularCSV) encodeNumbers(name string, values []string) ([][]float64, error) {
+	numbers := make([]float64, len(values))
+	isMissing := make([]bool, len(values))
+	sum, count := 0.0, 0
+	for i, value := range values {
+		if t.missing(value) {
+			isMissing[i] = true
+			continue
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %v: %v", name, err)
+		}
+		numbers[i] = number
+		sum += number
+		count += 1
+	}
+
+	fill, ok := t.Fill[name]
+	if !ok {
+		if count > 0 {
+			fill = sum / float64(count)
+		}
+		t.Fill[name] = fill
+	}
+
+	result := make([][]float64, len(values))
+	for i, number := range numbers {
+		if isMissing[i] {
+			number = fill
+		}
+		result[i] = []float64{number}
+	}
+	return result, nil
+}
+
+// returns index of the most frequent category; indexes of missing values are -1
+func mostFrequent(indexes []float64, categories int) float64 {
+	counts := make([]int, categories)
+	for _, index := range indexes {
+		if index >= 0 {
+			counts[int(index)] += 1
+		}
+	}
+	best := 0
+	for i, count := range counts {
+		if count > counts[best] {
+			best = i
+		}
+	}
+	return float64(best)
+}
+
+// places encoded columns side by side
+func joinColumns(columns [][][]float64) mat.Dense {
+	if len(columns) == 0 || len(columns[0]) == 0 {
+		return mat.Dense{}
+	}
+	rows, cols := len(columns[0]), 0
+	for _, column := range columns {
+		cols += len(column[0])
+	}
+
+	result := mat.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		row := result.RawRowView(i)[:0]
+		for _, column := range columns {
+			row = append(row, column[i]...)
+		}
+	}
+	return *result
+}
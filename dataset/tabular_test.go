@@ -0,0 +1,74 @@
+package dataset_test
+
+import (
+	"main/dataset"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestTabularCSV(t *testing.T) {
+	training := `age,city,income,label
+30,paris,100,yes
+,london,NA,no
+50,paris,300,yes
+40,,200,no
+`
+	loader := dataset.TabularCSV{
+		Header: true,
+		Features: []dataset.TabularColumn{
+			{Name: "city", Encoding: dataset.OneHotColumn},
+			{Name: "age"},
+			{Name: "income"},
+		},
+		Target:        dataset.TabularColumn{Name: "label", Encoding: dataset.LabelColumn},
+		MissingValues: []string{"NA"},
+	}
+
+	data, err := loader.Read(strings.NewReader(training))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// city is one-hot of [london, paris]; missing age and income are means, missing city is paris
+	expectedX := mat.NewDense(4, 4, []float64{
+		0, 1, 30, 100,
+		1, 0, 40, 200,
+		0, 1, 50, 300,
+		0, 1, 40, 200,
+	})
+	expectedY := mat.NewDense(4, 1, []float64{1, 0, 1, 0})
+	if !mat.Equal(expectedX, &data.X) {
+		t.Fatalf("Unexpected features:\n%v", mat.Formatted(&data.X))
+	}
+	if !mat.Equal(expectedY, &data.Y) {
+		t.Fatalf("Unexpected targets:\n%v", mat.Formatted(&data.Y))
+	}
+
+	// test file is encoded with categories and fill values of the training one
+	test, err := loader.Read(strings.NewReader("age,city,income,label\n,london,10,yes\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mat.Equal(mat.NewDense(1, 4, []float64{1, 0, 40, 10}), &test.X) || test.Y.At(0, 0) != 1 {
+		t.Fatalf("Unexpected test sample: %v %v", test.X.RawMatrix().Data, test.Y.RawMatrix().Data)
+	}
+
+	_, err = loader.Read(strings.NewReader("age,city,income,label\n1,berlin,1,yes\n"))
+	if err == nil {
+		t.Fatal("Unknown category has to be reported")
+	}
+}
+
+func TestTabularCSVDropMissing(t *testing.T) {
+	loader := dataset.TabularCSV{Target: dataset.TabularColumn{Name: "2"}, DropMissing: true}
+
+	data, err := loader.Read(strings.NewReader("1,2,3\n4,,6\n7,8,9\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mat.Equal(mat.NewDense(2, 2, []float64{1, 2, 7, 8}), &data.X) || !mat.Equal(mat.NewDense(2, 1, []float64{3, 9}), &data.Y) {
+		t.Fatalf("Unexpected data: %v %v", data.X.RawMatrix().Data, data.Y.RawMatrix().Data)
+	}
+}
@@ -0,0 +1,186 @@
+package gradcheck
+
+import (
+	"fmt"
+	"main/layer"
+	"main/model"
+	"math"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Checker compares gradients of Backward passes with central finite differences
+// every value of a checked tensor costs two Forward passes, so it's meant for small layers and models
+type Checker struct {
+	// step of finite differences; defaults to 1e-5
+	Epsilon float64
+	// runs Forward passes in training mode, e.g. with dropout masks and batch statistics
+	// note that batch normalization updates running statistics on every pass
+	Training bool
+	// seed of upstream gradients of checked layers and of sources of random layers
+	Seed uint64
+}
+
+// Result compares analytical and numerical gradients of one tensor
+type Result struct {
+	// "inputs" or name of the parameter; parameters of a model are prefixed with index of their layer, e.g. "0.weights"
+	Name string
+	// largest relative error |analytical - numerical| / max(|analytical|, |numerical|) over all values of the tensor
+	// differences smaller than 1e-8 are caused by rounding and are not counted as errors
+	Error float64
+	// position and gradients of the value with the largest error
+	Row, Col   int
+	Analytical float64
+	Numerical  float64
+}
+
+func (result Result) String() string {
+	return fmt.Sprintf("%v[%v, %v]: analytical %v, numerical %v, relative error %v",
+		result.Name, result.Row, result.Col, result.Analytical, result.Numerical, result.Error)
+}
+
+// Report lists results of all checked tensors
+type Report []Result
+
+// Worst returns result with the largest error
+func (report Report) Worst() Result {
+	worst := Result{}
+	for _, result := range report {
+		if result.Error >= worst.Error {
+			worst = result
+		}
+	}
+	return worst
+}
+
+// tensor with its gradients from the Backward pass
+type tensor struct {
+	name      string
+	values    *mat.Dense
+	gradients *mat.Dense
+}
+
+// Layer checks gradients of inputs and parameters of a layer
+// the layer output is reduced to a scalar with random weights, which become dvalues of Backward pass
+// regularization of parameters is added to the scalar, as loss functions do
+func (checker Checker) Layer(l layer.LayerInterface, inputs *mat.Dense) Report {
+	inputs = mat.DenseCopyOf(inputs)
+
+	checker.forwardLayer(l, inputs)
+	rows, cols := l.GetOutput().Dims()
+	random := rand.New(rand.NewSource(checker.Seed))
+	dvalues := mat.NewDense(rows, cols, nil)
+	for i := range dvalues.RawMatrix().Data {
+		dvalues.RawMatrix().Data[i] = random.NormFloat64()
+	}
+	l.Backward(dvalues)
+
+	tensors := []tensor{{name: "inputs", values: inputs, gradients: mat.DenseCopyOf(l.GetDInputs())}}
+	parameters := []layer.Parameter{}
+	// parameters are taken after Backward, as layers may reallocate gradients
+	if trainableLayer, ok := l.(layer.TrainableLayer); ok {
+		parameters = trainableLayer.Parameters()
+		for _, parameter := range parameters {
+			tensors = append(tensors, tensor{name: parameter.Name, values: parameter.Values, gradients: mat.DenseCopyOf(parameter.Gradients)})
+		}
+	}
+
+	return checker.compare(tensors, func() float64 {
+		checker.forwardLayer(l, inputs)
+		output := mat.DenseCopyOf(l.GetOutput())
+		output.MulElem(output, dvalues)
+		return mat.Sum(output) + regularization(parameters)
+	})
+}
+
+// Model checks gradients of parameters of all trainable layers of a finalized model
+// the scalar is the mean loss of data plus regularization loss, i.e. the loss minimized by Model.Train
+// random layers restart from Model.Seed on every pass
+func (checker Checker) Model(m *model.Model, data model.ModelData) Report {
+	output := checker.forwardModel(m, data.X)
+	m.Backward(*mat.DenseCopyOf(output), data.Y)
+
+	tensors := []tensor{}
+	for i, item := range m.Layers {
+		if trainableLayer, ok := item.(layer.TrainableLayer); ok {
+			for _, parameter := range trainableLayer.Parameters() {
+				name := fmt.Sprintf("%d.%s", i, parameter.Name)
+				tensors = append(tensors, tensor{name: name, values: parameter.Values, gradients: mat.DenseCopyOf(parameter.Gradients)})
+			}
+		}
+	}
+
+	return checker.compare(tensors, func() float64 {
+		output := checker.forwardModel(m, data.X)
+		sampleLosses := m.Loss.Forward(output, &data.Y)
+		return floats.Sum(sampleLosses)/float64(len(sampleLosses)) + m.Loss.RegularizationLoss()
+	})
+}
+
+func (checker Checker) forwardLayer(l layer.LayerInterface, inputs *mat.Dense) {
+	if randomLayer, ok := l.(layer.RandomLayer); ok {
+		randomLayer.SetRandomSource(rand.NewSource(checker.Seed))
+	}
+	l.Forward(inputs, checker.Training)
+}
+
+func (checker Checker) forwardModel(m *model.Model, x mat.Dense) *mat.Dense {
+	// reseeds the shared source of random layers
+	m.Finalize()
+	return m.Forward(x, checker.Training)
+}
+
+// perturbs every value of tensors in place and compares derivatives of objective with gradients
+func (checker Checker) compare(tensors []tensor, objective func() float64) Report {
+	epsilon := checker.Epsilon
+	if epsilon == 0 {
+		epsilon = 1e-5
+	}
+
+	report := make(Report, 0, len(tensors))
+	for _, t := range tensors {
+		result := Result{Name: t.name}
+		rows, cols := t.values.Dims()
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				value := t.values.At(i, j)
+				t.values.Set(i, j, value+epsilon)
+				plus := objective()
+				t.values.Set(i, j, value-epsilon)
+				minus := objective()
+				t.values.Set(i, j, value)
+
+				analytical := t.gradients.At(i, j)
+				numerical := (plus - minus) / (2 * epsilon)
+				if err := relativeError(analytical, numerical); err > result.Error || (i == 0 && j == 0) {
+					result.Error = err
+					result.Row, result.Col = i, j
+					result.Analytical, result.Numerical = analytical, numerical
+				}
+			}
+		}
+		report = append(report, result)
+	}
+	return report
+}
+
+func relativeError(analytical, numerical float64) float64 {
+	difference := math.Abs(analytical - numerical)
+	if difference < 1e-8 {
+		return 0
+	}
+	return difference / max(math.Abs(analytical), math.Abs(numerical))
+}
+
+// the same regularization loss as loss.BaseLoss.RegularizationLoss
+func regularization(parameters []layer.Parameter) float64 {
+	value := 0.0
+	for _, parameter := range parameters {
+		for _, v := range parameter.Values.RawMatrix().Data {
+			value += parameter.L1*math.Abs(v) + parameter.L2*v*v
+		}
+	}
+	return value
+}
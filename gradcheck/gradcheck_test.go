@@ -0,0 +1,166 @@
+package gradcheck_test
+
+import (
+	"main/accuracy"
+	"main/activation"
+	"main/augmentation"
+	"main/gradcheck"
+	"main/layer"
+	"main/loss"
+	"main/model"
+	"main/optimizations"
+	"main/optimizer"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+const tolerance = 1e-6
+
+func randomMatrix(rows, cols int, seed uint64) *mat.Dense {
+	random := rand.New(rand.NewSource(seed))
+	data := make([]float64, rows*cols)
+	for i := range data {
+		data[i] = random.Float64()*2 - 1
+	}
+	return mat.NewDense(rows, cols, data)
+}
+
+func checkReport(t *testing.T, report gradcheck.Report) {
+	t.Helper()
+	for _, result := range report {
+		if result.Error > tolerance {
+			t.Errorf("Incorrect gradients of %v", result)
+		}
+	}
+}
+
+func TestLayerGradients(t *testing.T) {
+	shape := layer.InputShape{Depths: 2, Height: 4, Width: 6}
+
+	cases := []struct {
+		name     string
+		layer    func() layer.LayerInterface
+		inputs   int
+		training bool
+	}{
+		{"dense", func() layer.LayerInterface {
+			l := (&layer.DenseLayer{}).Initialization(5, 3)
+			l.LoadFromParams(randomMatrix(5, 3, 2), randomMatrix(1, 3, 3), layer.Regularizer{Weight: 0.1, Bias: 0.2}, layer.Regularizer{Weight: 0.3, Bias: 0.4})
+			return l
+		}, 5, false},
+		{"dropout", func() layer.LayerInterface { return (&layer.DropoutLayer{}).Initialization(0.3) }, 5, true},
+		{"batch norm", func() layer.LayerInterface {
+			l := (&layer.BatchNormLayer{}).Initialization(5)
+			l.Gamma = *randomMatrix(1, 5, 2)
+			l.Beta = *randomMatrix(1, 5, 3)
+			return l
+		}, 5, true},
+		{"batch norm of channels", func() layer.LayerInterface {
+			l := (&layer.BatchNormLayer{}).InitializationWithShape(shape)
+			l.Gamma = *randomMatrix(1, 2, 2)
+			return l
+		}, shape.TotalSize(), true},
+		{"batch norm inference", func() layer.LayerInterface {
+			l := (&layer.BatchNormLayer{}).Initialization(5)
+			l.Gamma = *randomMatrix(1, 5, 2)
+			l.RunningVariance = *mat.NewDense(1, 5, []float64{1, 2, 3, 4, 5})
+			return l
+		}, 5, false},
+		{"convolution", func() layer.LayerInterface {
+			return (&layer.ConvolutionLayer{}).Initialization(shape, 3, 3)
+		}, shape.TotalSize(), false},
+		{"direct convolution", func() layer.LayerInterface {
+			options := layer.ConvolutionOptions{Stride: 1, Algorithm: layer.ConvolutionDirect}
+			return (&layer.ConvolutionLayer{}).InitializationWithOptions(shape, 3, 3, options)
+		}, shape.TotalSize(), false},
+		{"strided convolution with padding", func() layer.LayerInterface {
+			options := layer.ConvolutionOptions{Stride: 2, Padding: layer.PaddingSame}
+			return (&layer.ConvolutionLayer{}).InitializationWithOptions(shape, 3, 3, options)
+		}, shape.TotalSize(), false},
+		{"direct strided convolution with padding", func() layer.LayerInterface {
+			options := layer.ConvolutionOptions{Stride: 2, Padding: 2, Algorithm: layer.ConvolutionDirect}
+			return (&layer.ConvolutionLayer{}).InitializationWithOptions(shape, 3, 3, options)
+		}, shape.TotalSize(), false},
+		{"max pooling", func() layer.LayerInterface {
+			return (&layer.MaxPoolingLayer{}).Initialization(shape, 2)
+		}, shape.TotalSize(), false},
+		{"augmentation", func() layer.LayerInterface {
+			return (&augmentation.AugmentationLayer{}).Initialization(shape, &augmentation.HorizontalFlip{Probability: 1})
+		}, shape.TotalSize(), false},
+		{"relu", func() layer.LayerInterface { return &activation.Activation_ReLU{} }, 5, false},
+		{"sigmoid", func() layer.LayerInterface { return &activation.SigmoidActivation{} }, 5, false},
+		{"softmax", func() layer.LayerInterface { return &activation.SoftmaxActivation{} }, 5, false},
+		{"linear", func() layer.LayerInterface { return &activation.LinearActivation{} }, 5, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			checker := gradcheck.Checker{Training: c.training, Seed: 1}
+			checkReport(t, checker.Layer(c.layer(), randomMatrix(3, c.inputs, 4)))
+		})
+	}
+}
+
+func newModel(last layer.LayerInterface, l loss.LossInterface, a accuracy.AccuracyInterface) *model.Model {
+	m := model.Model{Seed: 5}
+	dense := (&layer.DenseLayer{}).Initialization(4, 6)
+	dense.LoadFromParams(randomMatrix(4, 6, 2), randomMatrix(1, 6, 3), layer.Regularizer{Weight: 1e-2}, layer.Regularizer{Weight: 1e-2, Bias: 1e-2})
+	m.Add(dense)
+	m.Add((&layer.BatchNormLayer{}).Initialization(6))
+	m.Add(&activation.Activation_ReLU{})
+	m.Add((&layer.DropoutLayer{}).Initialization(0.2))
+	output := (&layer.DenseLayer{}).Initialization(6, 3)
+	output.LoadFromParams(randomMatrix(6, 3, 6), randomMatrix(1, 3, 7), layer.Regularizer{}, layer.Regularizer{})
+	m.Add(output)
+	m.Add(last)
+
+	o := optimizer.NewSGD(0.1, 0, 0)
+	m.Set(l, &o, a)
+	m.Finalize()
+	return &m
+}
+
+func TestModelGradients(t *testing.T) {
+	x := *randomMatrix(6, 4, 8)
+	classes := *mat.NewDense(6, 1, []float64{0, 1, 2, 2, 1, 0})
+	optimizedActivation, optimizedLoss := optimizations.MakeOptimizedCategorialCrossentropy()
+
+	cases := []struct {
+		name  string
+		model *model.Model
+		y     mat.Dense
+	}{
+		{"categorical crossentropy", newModel(&activation.SoftmaxActivation{}, &loss.CategoricalCrossentropyLoss{}, &accuracy.CategorialAccuracy{}), classes},
+		{"optimized categorical crossentropy", newModel(&optimizedActivation, &optimizedLoss, &accuracy.CategorialAccuracy{}), classes},
+		{"binary crossentropy", newModel(&activation.SigmoidActivation{}, &loss.BinaryCrossentropyLoss{}, &accuracy.BinaryCategorialAccuracy{}), *mat.NewDense(6, 3, []float64{0, 1, 1, 1, 0, 0, 1, 1, 0, 0, 0, 1, 1, 0, 1, 0, 1, 0})},
+		{"mean squared error", newModel(&activation.LinearActivation{}, &loss.MeanSquaredErrorLoss{}, &accuracy.BinaryCategorialAccuracy{}), *randomMatrix(6, 3, 9)},
+		{"mean absolute error", newModel(&activation.LinearActivation{}, &loss.MeanAbsoluteErrorLoss{}, &accuracy.BinaryCategorialAccuracy{}), *randomMatrix(6, 3, 9)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			checker := gradcheck.Checker{Training: true}
+			checkReport(t, checker.Model(c.model, model.ModelData{X: x, Y: c.y}))
+		})
+	}
+}
+
+// multiplies gradients by 2 to make sure the checker notices wrong Backward passes
+type brokenActivation struct {
+	activation.LinearActivation
+}
+
+func (a *brokenActivation) Backward(dvalues *mat.Dense) {
+	a.LinearActivation.Backward(dvalues)
+	a.DInputs.Scale(2, &a.DInputs)
+}
+
+func TestCheckerFindsIncorrectGradients(t *testing.T) {
+	report := gradcheck.Checker{}.Layer(&brokenActivation{}, randomMatrix(2, 3, 1))
+	worst := report.Worst()
+	if worst.Name != "inputs" || worst.Error < 0.3 {
+		t.Fatalf("Incorrect gradients are not found: %v", worst)
+	}
+}
@@ -0,0 +1,228 @@
+package layer
+
+import (
+	"log"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type BatchNormLayer struct {
+	// number of normalized channels. Every channel has its own mean, variance, gamma and beta
+	Channels int
+	// number of values in one channel of a sample
+	// 1 for output of Dense Layer; Height * Width for output of Convolution Layer
+	ChannelSize int
+
+	// defines how fast running statistics follow batch statistics
+	Momentum float64
+	// added to variance to avoid division by zero
+	Epsilon float64
+
+	// Trainable params
+	// one row with cols equal to Channels
+	Gamma mat.Dense
+	Beta  mat.Dense
+
+	// statistics collected during training and used for inference
+	// one row with cols equal to Channels
+	RunningMean     mat.Dense
+	RunningVariance mat.Dense
+
+	Output mat.Dense
+
+	// Backward pass
+	DGamma  mat.Dense
+	DBeta   mat.Dense
+	DInputs mat.Dense
+
+	// state of optimizer for Gamma and Beta
+	OptimizerState OptimizerState
+
+	// values from Forward to be used in Backward
+	normalized mat.Dense
+	stdDev     []float64
+	// Forward normalized inputs with statistics of the batch rather than running ones
+	batchStatistics bool
+}
+
+func (layer *BatchNormLayer) Name() string {
+	return "Batch Normalization Layer"
+}
+
+// normalizes every input value separately, e.g. output of Dense Layer
+func (layer *BatchNormLayer) Initialization(n_inputs int) *BatchNormLayer {
+	return layer.initialization(n_inputs, 1)
+}
+
+// normalizes every channel of the shape, e.g. output of Convolution Layer
+func (layer *BatchNormLayer) InitializationWithShape(shape InputShape) *BatchNormLayer {
+	return layer.initialization(shape.Depths, shape.Height*shape.Width)
+}
+
+// normalizes channels of channelSize values each, e.g. when restoring a stored layer
+func NewBatchNormLayer(channels int, channelSize int) *BatchNormLayer {
+	return (&BatchNormLayer{}).initialization(channels, channelSize)
+}
+
+func (layer *BatchNormLayer) initialization(channels int, channelSize int) *BatchNormLayer {
+	layer.Channels = channels
+	layer.ChannelSize = channelSize
+	layer.Momentum = 0.99
+	layer.Epsilon = 1e-5
+
+	ones := make([]float64, channels)
+	for i := range ones {
+		ones[i] = 1.0
+	}
+	layer.Gamma = *mat.NewDense(1, channels, ones)
+	layer.Beta = *mat.NewDense(1, channels, nil)
+	layer.RunningMean = *mat.NewDense(1, channels, nil)
+	layer.RunningVariance = *mat.NewDense(1, channels, append([]float64{}, ones...))
+	layer.zeroGradients()
+
+	return layer
+}
+
+func (layer *BatchNormLayer) LoadFromParams(channels int, channelSize int, momentum float64, epsilon float64, gamma *mat.Dense, beta *mat.Dense, runningMean *mat.Dense, runningVariance *mat.Dense) {
+	layer.Channels = channels
+	layer.ChannelSize = channelSize
+	layer.Momentum = momentum
+	layer.Epsilon = epsilon
+	layer.Gamma = *mat.DenseCopyOf(gamma)
+	layer.Beta = *mat.DenseCopyOf(beta)
+	layer.RunningMean = *mat.DenseCopyOf(runningMean)
+	layer.RunningVariance = *mat.DenseCopyOf(runningVariance)
+	layer.zeroGradients()
+}
+
+// gradients exist before the first Backward pass, so Parameters can be used by optimizers right away
+func (layer *BatchNormLayer) zeroGradients() {
+	layer.DGamma = *mat.NewDense(1, layer.Channels, nil)
+	layer.DBeta = *mat.NewDense(1, layer.Channels, nil)
+}
+
+func (layer *BatchNormLayer) Forward(inputs *mat.Dense, isTraining bool) {
+	samples, cols := inputs.Dims()
+	if cols != layer.Channels*layer.ChannelSize {
+		log.Fatalf("Unexpected input size: %v with %v channels of size %v", cols, layer.Channels, layer.ChannelSize)
+	}
+	count := float64(samples * layer.ChannelSize)
+	layer.batchStatistics = isTraining
+
+	mean := make([]float64, layer.Channels)
+	variance := make([]float64, layer.Channels)
+
+	if isTraining {
+		// statistics of the current batch
+		for c := 0; c < layer.Channels; c++ {
+			for i := 0; i < samples; i++ {
+				for _, v := range layer.channel(inputs, i, c) {
+					mean[c] += v
+				}
+			}
+			mean[c] /= count
+
+			for i := 0; i < samples; i++ {
+				for _, v := range layer.channel(inputs, i, c) {
+					variance[c] += math.Pow(v-mean[c], 2)
+				}
+			}
+			variance[c] /= count
+
+			runningMean := layer.Momentum*layer.RunningMean.At(0, c) + (1-layer.Momentum)*mean[c]
+			runningVariance := layer.Momentum*layer.RunningVariance.At(0, c) + (1-layer.Momentum)*variance[c]
+			layer.RunningMean.Set(0, c, runningMean)
+			layer.RunningVariance.Set(0, c, runningVariance)
+		}
+	} else {
+		// inference uses statistics collected during training
+		copy(mean, layer.RunningMean.RawRowView(0))
+		copy(variance, layer.RunningVariance.RawRowView(0))
+	}
+
+	layer.stdDev = make([]float64, layer.Channels)
+	for c := range layer.stdDev {
+		layer.stdDev[c] = math.Sqrt(variance[c] + layer.Epsilon)
+	}
+
+	layer.normalized = *mat.DenseCopyOf(inputs)
+	layer.Output = *mat.DenseCopyOf(inputs)
+	for i := 0; i < samples; i++ {
+		for c := 0; c < layer.Channels; c++ {
+			normalized := layer.channel(&layer.normalized, i, c)
+			output := layer.channel(&layer.Output, i, c)
+			for s := range normalized {
+				normalized[s] = (normalized[s] - mean[c]) / layer.stdDev[c]
+				output[s] = layer.Gamma.At(0, c)*normalized[s] + layer.Beta.At(0, c)
+			}
+		}
+	}
+}
+
+func (layer *BatchNormLayer) Backward(dvalues *mat.Dense) {
+	samples, _ := dvalues.Dims()
+	count := float64(samples * layer.ChannelSize)
+
+	layer.zeroGradients()
+	layer.DInputs = *mat.DenseCopyOf(dvalues)
+
+	for c := 0; c < layer.Channels; c++ {
+		// sums over the whole batch are used by every input gradient
+		dGamma, dBeta := 0.0, 0.0
+		for i := 0; i < samples; i++ {
+			normalized := layer.channel(&layer.normalized, i, c)
+			for s, v := range layer.channel(dvalues, i, c) {
+				dGamma += v * normalized[s]
+				dBeta += v
+			}
+		}
+		layer.DGamma.Set(0, c, dGamma)
+		layer.DBeta.Set(0, c, dBeta)
+
+		scale := layer.Gamma.At(0, c) / layer.stdDev[c]
+		for i := 0; i < samples; i++ {
+			normalized := layer.channel(&layer.normalized, i, c)
+			dinputs := layer.channel(&layer.DInputs, i, c)
+			for s := range dinputs {
+				if layer.batchStatistics {
+					// dinputs = gamma / std * (dvalues - mean(dvalues) - normalized * mean(dvalues * normalized))
+					dinputs[s] = scale * (dinputs[s] - dBeta/count - normalized[s]*dGamma/count)
+				} else {
+					// running statistics don't depend on inputs
+					dinputs[s] = scale * dinputs[s]
+				}
+			}
+		}
+	}
+}
+
+func (layer *BatchNormLayer) Parameters() []Parameter {
+	return []Parameter{
+		{
+			Name:      "gamma",
+			Values:    &layer.Gamma,
+			Gradients: &layer.DGamma,
+			State:     layer.OptimizerState.For("gamma"),
+		},
+		{
+			Name:      "beta",
+			Values:    &layer.Beta,
+			Gradients: &layer.DBeta,
+			State:     layer.OptimizerState.For("beta"),
+		},
+	}
+}
+
+func (layer *BatchNormLayer) GetOutput() *mat.Dense {
+	return &layer.Output
+}
+
+func (layer *BatchNormLayer) GetDInputs() *mat.Dense {
+	return &layer.DInputs
+}
+
+// returns values of channel c from sample i; slice shares data with values
+func (layer *BatchNormLayer) channel(values *mat.Dense, i int, c int) []float64 {
+	return values.RawRowView(i)[c*layer.ChannelSize : (c+1)*layer.ChannelSize]
+}
@@ -0,0 +1,81 @@
+package layer_test
+
+import (
+	"main/layer"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+func TestBatchNormTrainingForward(t *testing.T) {
+	l := layer.BatchNormLayer{}
+	l.Initialization(2)
+
+	inputData := mat.NewDense(4, 2, []float64{1, 10, 2, 20, 3, 30, 4, 40})
+	l.Forward(inputData, true)
+
+	for j := 0; j < 2; j++ {
+		column := mat.Col(nil, j, &l.Output)
+		mean, std := stat.PopMeanStdDev(column, nil)
+		if math.Abs(mean) > 1e-9 || math.Abs(std-1) > 1e-3 {
+			t.Fatalf("Output is not normalized: mean %v std %v", mean, std)
+		}
+	}
+
+	if math.Abs(l.RunningMean.At(0, 1)-0.01*25) > 1e-9 {
+		t.Fatalf("Incorrect running mean: %v", l.RunningMean.At(0, 1))
+	}
+}
+
+func TestBatchNormInferenceForward(t *testing.T) {
+	l := layer.BatchNormLayer{}
+	l.InitializationWithShape(layer.InputShape{Depths: 2, Height: 1, Width: 2})
+	l.Epsilon = 0
+	l.RunningMean = *mat.NewDense(1, 2, []float64{1, -1})
+	l.RunningVariance = *mat.NewDense(1, 2, []float64{4, 1})
+	l.Beta = *mat.NewDense(1, 2, []float64{0, 0.5})
+
+	inputData := mat.NewDense(1, 4, []float64{3, 5, -1, 0})
+	l.Forward(inputData, false)
+
+	expected := []float64{1, 2, 0.5, 1.5}
+	if !isClose(l.Output.RawMatrix().Data, expected) {
+		t.Fatalf("Unexpected output: %v", l.Output.RawMatrix().Data)
+	}
+	if l.RunningMean.At(0, 0) != 1 {
+		t.Fatal("Running statistics should not change during inference")
+	}
+}
+
+func TestBatchNormInferenceBackward(t *testing.T) {
+	l := layer.BatchNormLayer{}
+	l.Initialization(2)
+	l.Epsilon = 0
+	l.Gamma = *mat.NewDense(1, 2, []float64{2, 3})
+	l.RunningVariance = *mat.NewDense(1, 2, []float64{4, 9})
+
+	l.Forward(mat.NewDense(2, 2, []float64{1, 2, 3, 4}), false)
+	l.Backward(mat.NewDense(2, 2, []float64{1, 1, 2, -1}))
+
+	// gamma / std * dvalues
+	expected := []float64{1, 1, 2, -1}
+	if !isClose(l.DInputs.RawMatrix().Data, expected) {
+		t.Fatalf("Unexpected input gradients: %v", l.DInputs.RawMatrix().Data)
+	}
+}
+
+func TestBatchNormGradientsBeforeBackward(t *testing.T) {
+	l := layer.BatchNormLayer{}
+	l.Initialization(3)
+	loaded := layer.BatchNormLayer{}
+	loaded.LoadFromParams(l.Channels, l.ChannelSize, l.Momentum, l.Epsilon, &l.Gamma, &l.Beta, &l.RunningMean, &l.RunningVariance)
+
+	for _, parameter := range append(l.Parameters(), loaded.Parameters()...) {
+		rows, cols := parameter.Gradients.Dims()
+		if rows != 1 || cols != 3 {
+			t.Fatalf("Unexpected shape of %v gradients: %vx%v", parameter.Name, rows, cols)
+		}
+	}
+}
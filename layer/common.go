@@ -2,7 +2,10 @@ package layer
 
 import (
 	"math"
+	"runtime"
+	"sync"
 
+	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -16,6 +19,13 @@ type LayerInterface interface {
 	Backward(dvalues *mat.Dense)
 }
 
+// RandomLayer uses random numbers in training mode
+// model provides one shared source, so its state can be stored in training checkpoints
+type RandomLayer interface {
+	LayerInterface
+	SetRandomSource(source rand.Source)
+}
+
 // takes raw data from one sample for inputs and slices it according to InputShape
 // e.g., Grayscake will return one mat.Dense
 // RGB - len == 3
@@ -40,3 +50,25 @@ func MaxValue(m mat.Matrix) float64 {
 
 	return maxValue
 }
+
+// calls f for every index in 0..<count using one goroutine per CPU
+func parallelFor(count int, f func(i int)) {
+	workers := min(runtime.NumCPU(), count)
+	indexes := make(chan int, count)
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				f(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
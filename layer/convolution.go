@@ -1,11 +1,14 @@
 package layer
 
 import (
+	"fmt"
 	"log"
 	"main/ops"
 	"math/rand"
 	"sync"
 
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas64"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -21,9 +24,17 @@ type ConvolutionLayer struct {
 	// Defines the side of kenlel matrix. Kernels are always square, so any kenle will be (KernelSize, KernelSize)
 	KernelSize int
 
+	// Step of the kernel over input data
+	Stride int
+	// Number of zero rows and columns added to every side of input channels
+	Padding int
+
+	// Defines how Forward and Backward are computed
+	Algorithm ConvolutionAlgorithm
+
 	// Defines the shape of Convolution layer
 	// In this case Depths is equal to Depths of Convolution
-	// W&H is calculated based on input shape and kernel size
+	// W&H is calculated based on input shape, kernel size, stride and padding
 	OutputShape InputShape
 
 	// Defines shape of DS to store kernels data corresponding to Convolution Depths and Input Depths
@@ -47,10 +58,40 @@ type ConvolutionLayer struct {
 	// cols -> should be of InputShape shape
 	DInputs mat.Dense
 
-	// derivatives to adjust Kernels
-	dKernels [][][]mat.Dense
-	// derivatives to adjust Biases
-	dvalues mat.Dense
+	// derivatives with respect to Kernels summed over all input samples
+	DKernels [][]mat.Dense
+	// derivatives with respect to Biases summed over all input samples
+	DBiases []mat.Dense
+
+	// state of optimizer for Kernels and Biases
+	OptimizerState OptimizerState
+}
+
+// Padding values with special meaning for ConvolutionOptions
+const (
+	// no padding, kernel is applied only where it fully overlaps input
+	PaddingValid = 0
+	// padding (KernelSize - 1) / 2 which keeps output size equal to input size for stride 1
+	PaddingSame = -1
+)
+
+// defines how convolution is computed. Both algorithms give the same results
+type ConvolutionAlgorithm int
+
+const (
+	// unrolls input patches into matrix and uses matrix multiplication (BLAS)
+	ConvolutionIm2Col ConvolutionAlgorithm = iota
+	// cross-correlation for every kernel and input channel pair. Slow, but close to the math
+	ConvolutionDirect
+)
+
+type ConvolutionOptions struct {
+	// defaults to 1
+	Stride int
+	// explicit padding or one of PaddingValid and PaddingSame
+	Padding int
+	// defaults to ConvolutionIm2Col
+	Algorithm ConvolutionAlgorithm
 }
 
 type InputShape struct {
@@ -79,20 +120,39 @@ func (shape KernelShape) TotalSize() int {
 }
 
 func (layer *ConvolutionLayer) Initialization(inputShape InputShape, convolutionDepths int, kernelSize int) *ConvolutionLayer {
+	return layer.InitializationWithOptions(inputShape, convolutionDepths, kernelSize, ConvolutionOptions{Stride: 1, Padding: PaddingValid})
+}
+
+func (layer *ConvolutionLayer) InitializationWithOptions(inputShape InputShape, convolutionDepths int, kernelSize int, options ConvolutionOptions) *ConvolutionLayer {
 	// I will need
 	// 1. something that described input shape
 	// 2. depths of convolution == number of kernels
-	// 3. and kernel size
+	// 3. kernel size
+	// 4. stride and padding
 	layer.InputShape = inputShape
 	layer.Depths = convolutionDepths
 	layer.KernelSize = kernelSize
+	layer.Algorithm = options.Algorithm
 
-	// output is defined by convolution depths and relation between input size and kernel size
-	// this is simplified version of computation that uses "1" as stride and "0" padding
-	layer.OutputShape = InputShape{
-		Depths: convolutionDepths,
-		Height: inputShape.Height - kernelSize + 1,
-		Width:  inputShape.Width - kernelSize + 1,
+	layer.Stride = options.Stride
+	if layer.Stride == 0 {
+		layer.Stride = 1
+	}
+	layer.Padding = options.Padding
+	if layer.Padding == PaddingSame {
+		if kernelSize%2 == 0 {
+			log.Fatalf("Same padding requires odd kernel size, got: %v", kernelSize)
+		}
+		layer.Padding = (kernelSize - 1) / 2
+	}
+	if layer.Stride < 0 || layer.Padding < 0 {
+		log.Fatalf("Unexpected stride: %v or padding: %v", layer.Stride, layer.Padding)
+	}
+
+	// output is defined by convolution depths and relation between input size, kernel size, stride and padding
+	layer.OutputShape = ConvolutionOutputShape(inputShape, convolutionDepths, kernelSize, layer.Stride, layer.Padding)
+	if layer.OutputShape.Height < 1 || layer.OutputShape.Width < 1 {
+		log.Fatalf("Kernel size: %v does not fit InputShape: %v", kernelSize, inputShape)
 	}
 
 	layer.KernelShape = KernelShape{
@@ -135,10 +195,21 @@ func (layer *ConvolutionLayer) Initialization(inputShape InputShape, convolution
 	return layer
 }
 
-func (layer *ConvolutionLayer) LoadFromParams(inputShape InputShape, depths int, kernelSize int, outputShape InputShape, kernelShape KernelShape, kernels [][]mat.Dense, biases []mat.Dense) {
+// output size of every channel is (N + 2P - K) / S + 1
+func ConvolutionOutputShape(inputShape InputShape, depths int, kernelSize int, stride int, padding int) InputShape {
+	return InputShape{
+		Depths: depths,
+		Height: (inputShape.Height+2*padding-kernelSize)/stride + 1,
+		Width:  (inputShape.Width+2*padding-kernelSize)/stride + 1,
+	}
+}
+
+func (layer *ConvolutionLayer) LoadFromParams(inputShape InputShape, depths int, kernelSize int, stride int, padding int, outputShape InputShape, kernelShape KernelShape, kernels [][]mat.Dense, biases []mat.Dense) {
 	layer.InputShape = inputShape
 	layer.Depths = depths
 	layer.KernelSize = kernelSize
+	layer.Stride = stride
+	layer.Padding = padding
 	layer.OutputShape = outputShape
 	layer.KernelShape = kernelShape
 	layer.Kernels = kernels
@@ -172,6 +243,53 @@ func (layer *ConvolutionLayer) Forward(inputs *mat.Dense, isTraining bool) {
 		layer.Output.SetRow(i, allBiases)
 	}
 
+	if layer.Algorithm == ConvolutionDirect {
+		layer.forwardDirect(inputs)
+	} else {
+		layer.forwardIm2Col(inputs)
+	}
+
+	// Output shape will be number of input samples * layer.OutputShape.TotalSize()
+}
+
+// dvalues comes form max pooling (well, via relu)
+func (layer *ConvolutionLayer) Backward(dvalues *mat.Dense) {
+	// okay, now I can start building Backward pass
+	// 1. Init DInputs, DKernels and DBiases
+	// 2. Do valid and full cross-correlation (or the same with im2col)
+	// 3. params are adjusted later by the optimizer
+
+	inputSampleCount, _ := layer.Inputs.Dims()
+
+	// derivatives with respect to input
+	// we will use layer.DInputs directly, but it will require to write some index offet to write
+	layer.DInputs = *mat.NewDense(inputSampleCount, layer.InputShape.TotalSize(), nil)
+	layer.DInputs.Zero()
+
+	// derivatives with respect to kernel values
+	// uses the same data structure as Kernels; values from all samples are summed
+	layer.DKernels = zerosLikeKernels(layer.Kernels)
+
+	if layer.Algorithm == ConvolutionDirect {
+		layer.backwardDirect(dvalues)
+	} else {
+		layer.backwardIm2Col(dvalues)
+	}
+
+	// derivatives with respect to biases are dvalues themselves summed over all samples
+	layer.DBiases = zerosLikeBiases(layer.Biases)
+	for k := 0; k < inputSampleCount; k++ {
+		dvalue := ConvertSampleData(dvalues.RawRowView(k), layer.OutputShape)
+		for i := 0; i < len(layer.DBiases); i++ {
+			layer.DBiases[i].Add(&layer.DBiases[i], &dvalue[i])
+		}
+	}
+}
+
+// computes every output value with cross-correlation of input channels and kernels
+func (layer *ConvolutionLayer) forwardDirect(inputs *mat.Dense) {
+	inputSampleCount, _ := inputs.Dims()
+
 	wg := sync.WaitGroup{}
 	m := sync.Mutex{}
 
@@ -189,7 +307,7 @@ func (layer *ConvolutionLayer) Forward(inputs *mat.Dense, isTraining bool) {
 				// going via all sub-kernels (values from 1 kernel for each input channel)
 				for j := 0; j < layer.KernelShape.InputDepths; j++ {
 					// convolution result for every sub-kernel
-					convResult, err := ops.Correlate2dValid(inputSample[j], layer.Kernels[i][j])
+					convResult, err := ops.Correlate2d(inputSample[j], layer.Kernels[i][j], layer.Stride, layer.Padding)
 					if err != nil {
 						log.Fatal(err)
 					}
@@ -218,42 +336,26 @@ func (layer *ConvolutionLayer) Forward(inputs *mat.Dense, isTraining bool) {
 	}
 
 	wg.Wait()
-
-	// Output shape will be number of input samples * layer.OutputShape.TotalSize()
 }
 
-// dvalues comes form max pooling (well, via relu)
-func (layer *ConvolutionLayer) Backward(dvalues *mat.Dense) {
-	layer.dvalues = *mat.DenseCopyOf(dvalues)
-	// okay, now I can start building Backward pass
-	// 1. Init DInputs and DWeights (but I would not need to expose them as I will do weights adjustments right here)
-	// 2. Do valid and full cross-correlation
-	// 3. adjust params
+// unrolls input patches with im2col, so all kernels are applied with one matrix multiplication per sample
+func (layer *ConvolutionLayer) forwardIm2Col(inputs *mat.Dense) {
+	inputSampleCount, _ := inputs.Dims()
+	kernels := layer.kernelsMatrix()
+	outputSize := layer.OutputShape.Height * layer.OutputShape.Width
+
+	parallelFor(inputSampleCount, func(k int) {
+		cols := ops.Im2Col(inputs.RawRowView(k), layer.InputShape.Depths, layer.InputShape.Height, layer.InputShape.Width, layer.KernelSize, layer.Stride, layer.Padding)
+		// Output row already has biases, so the result of multiplication is added to it
+		// rows -> convolution depths; cols -> output values of one convolution
+		output := mat.NewDense(layer.Depths, outputSize, layer.Output.RawRowView(k))
+		blas64.Gemm(blas.NoTrans, blas.NoTrans, 1, kernels.RawMatrix(), cols.RawMatrix(), 1, output.RawMatrix())
+	})
+}
 
+func (layer *ConvolutionLayer) backwardDirect(dvalues *mat.Dense) {
 	inputSampleCount, _ := layer.Inputs.Dims()
 
-	// derivatives with respect to input
-	// we will use layer.DInputs directly, but it will require to write some index offet to write
-	layer.DInputs = *mat.NewDense(inputSampleCount, layer.InputShape.TotalSize(), nil)
-	layer.DInputs.Zero()
-
-	// derivatives with respect to kernel values
-	// will need to adjust Kernels -> using the same data structure
-
-	// layer.dKernels should have one more dimention equal to number of samples
-	// adjust params right inside `for k in 0..<inputSampleCount` loop
-	layer.dKernels = make([][][]mat.Dense, inputSampleCount)
-	for k := 0; k < inputSampleCount; k++ {
-		layer.dKernels[k] = make([][]mat.Dense, layer.KernelShape.Depths)
-		for i := 0; i < layer.KernelShape.Depths; i++ {
-			layer.dKernels[k][i] = make([]mat.Dense, layer.KernelShape.InputDepths)
-			for j := 0; j < layer.KernelShape.InputDepths; j++ {
-				layer.dKernels[k][i][j] = *mat.DenseCopyOf(&layer.Kernels[i][j])
-				layer.dKernels[k][i][j].Zero()
-			}
-		}
-	}
-
 	wg := sync.WaitGroup{}
 	m := sync.Mutex{}
 
@@ -268,37 +370,50 @@ func (layer *ConvolutionLayer) Backward(dvalues *mat.Dense) {
 			dvalue := ConvertSampleData(dvalues.RawRowView(k), layer.OutputShape)
 			// going thought all kernels within convolution layer
 			for i := 0; i < layer.KernelShape.Depths; i++ {
+				// with stride > 1 every dvalue affects inputs that are stride apart
+				// dilated dvalue puts zeros in between, so the rest is the same as for stride == 1
+				dilatedDValue := ops.Dilate2d(dvalue[i], layer.Stride)
+
 				// going via all sub-kernels (values from 1 kernel for each input channel)
 				for j := 0; j < layer.KernelShape.InputDepths; j++ {
 
-					// valid cross-correlation between input channel j and dvalues i -> Conv::Depths * Input::Depths results
-					// for input channel I have inputSample[i]
-					validCorrelateResult, err := ops.Correlate2dValid(inputSample[j], dvalue[i])
+					// valid cross-correlation between padded input channel j and dvalues i -> Conv::Depths * Input::Depths results
+					// result can be bigger than kernel when stride does not divide input size. Those values are not used by forward pass
+					validCorrelateResult, err := ops.Correlate2dValid(ops.Pad2d(inputSample[j], layer.Padding), dilatedDValue)
 					if err != nil {
 						log.Fatal(err)
 					}
+					dKernel := validCorrelateResult.Slice(0, layer.KernelSize, 0, layer.KernelSize)
 
-					// full cross-correlation between i-th dvalue and [i][j] Kernel values
-					// layer.DInputs =
-					fullCorrelateResult, err := ops.Correlate2DFull(dvalue[i], layer.Kernels[i][j])
+					// full convolution between i-th dvalue and [i][j] Kernel values
+					// which is full cross-correlation with Kernel rotated by 180 degrees
+					// result corresponds to padded input, so padding has to be skipped
+					fullConvolveResult, err := ops.Correlate2DFull(dilatedDValue, ops.Rotate180(layer.Kernels[i][j]))
 					if err != nil {
 						log.Fatal(err)
 					}
+					resultRows, resultCols := fullConvolveResult.Dims()
 
 					m.Lock()
-					layer.dKernels[k][i][j] = validCorrelateResult
+					layer.DKernels[i][j].Add(&layer.DKernels[i][j], dKernel)
 					// Now the question how to write stuff  into DInputs
 					// as layer.Dinputs is 2D array where one row will contain all the values
 					// one row has shape on InputShape (as it corresponds to input data)
-					// We need to sum fullCorrelateResult into dinputs[j] (so conv depths number of times for each input data channel)
-					// To do this, we need a way to calculate a slices from layer.DInputs.Row(j)
-					data := fullCorrelateResult.RawMatrix().Data
+					// We need to sum fullConvolveResult into dinputs[j] (so conv depths number of times for each input data channel)
 					startI := j * layer.InputShape.Width * layer.InputShape.Height
 					// Sum dinput values according to input channel
-					for ii := 0; ii < len(data); ii++ {
-						idx := startI + ii
-						v := layer.DInputs.At(k, idx)
-						layer.DInputs.Set(k, idx, v+data[i])
+					for ii := 0; ii < layer.InputShape.Height; ii++ {
+						for jj := 0; jj < layer.InputShape.Width; jj++ {
+							ri := ii + layer.Padding
+							rj := jj + layer.Padding
+							if ri >= resultRows || rj >= resultCols {
+								// input values that were never covered by kernel
+								continue
+							}
+							idx := startI + ii*layer.InputShape.Width + jj
+							v := layer.DInputs.At(k, idx)
+							layer.DInputs.Set(k, idx, v+fullConvolveResult.At(ri, rj))
+						}
 					}
 					m.Unlock()
 				}
@@ -308,32 +423,87 @@ func (layer *ConvolutionLayer) Backward(dvalues *mat.Dense) {
 	wg.Wait()
 }
 
-func (layer *ConvolutionLayer) UpdateParams(learningRate float64) {
-	// going via all used samples during Backward
-	for k := 0; k < len(layer.dKernels); k++ {
-		dKernelValues := layer.dKernels[k]
-
-		for i := 0; i < len(layer.Kernels); i++ {
-			for j := 0; j < len(layer.Kernels[i]); j++ {
-				tmp := mat.DenseCopyOf(&dKernelValues[i][j])
-				// adjusting derivatives by current learningRate
-				tmp.Scale(learningRate, &dKernelValues[i][j])
-				// adjusting Kernel values
-				layer.Kernels[i][j].Sub(&layer.Kernels[i][j], tmp)
-			}
+func (layer *ConvolutionLayer) backwardIm2Col(dvalues *mat.Dense) {
+	inputSampleCount, _ := layer.Inputs.Dims()
+	kernels := layer.kernelsMatrix()
+	outputSize := layer.OutputShape.Height * layer.OutputShape.Width
+
+	// derivatives with respect to kernels in the same layout as kernelsMatrix
+	dKernels := mat.NewDense(layer.KernelShape.Depths, layer.KernelShape.InputDepths*layer.KernelSize*layer.KernelSize, nil)
+	m := sync.Mutex{}
+
+	parallelFor(inputSampleCount, func(k int) {
+		cols := ops.Im2Col(layer.Inputs.RawRowView(k), layer.InputShape.Depths, layer.InputShape.Height, layer.InputShape.Width, layer.KernelSize, layer.Stride, layer.Padding)
+		dvalue := mat.NewDense(layer.Depths, outputSize, dvalues.RawRowView(k))
+
+		// dKernels = dvalues * cols^T
+		sampleDKernels := mat.NewDense(layer.Depths, layer.InputShape.Depths*layer.KernelSize*layer.KernelSize, nil)
+		sampleDKernels.Mul(dvalue, cols.T())
+
+		// dCols = kernels^T * dvalues; col2im sums overlapping patches into input shape
+		dCols := mat.NewDense(layer.InputShape.Depths*layer.KernelSize*layer.KernelSize, outputSize, nil)
+		dCols.Mul(kernels.T(), dvalue)
+		layer.DInputs.SetRow(k, ops.Col2Im(dCols, layer.InputShape.Depths, layer.InputShape.Height, layer.InputShape.Width, layer.KernelSize, layer.Stride, layer.Padding))
+
+		m.Lock()
+		dKernels.Add(dKernels, sampleDKernels)
+		m.Unlock()
+	})
+
+	kernelSize := layer.KernelSize * layer.KernelSize
+	for i := 0; i < layer.KernelShape.Depths; i++ {
+		row := dKernels.RawRowView(i)
+		for j := 0; j < layer.KernelShape.InputDepths; j++ {
+			copy(layer.DKernels[i][j].RawMatrix().Data, row[j*kernelSize:(j+1)*kernelSize])
 		}
+	}
+}
 
-		dBiasesValues := layer.dvalues.RawRowView(k)
-		for bI := 0; bI < len(layer.Biases); bI++ {
-			startI := bI * layer.OutputShape.Height * layer.OutputShape.Width
-			biasData := layer.Biases[bI].RawMatrix()
-			for ii := 0; ii < len(biasData.Data); ii++ {
-				// TODO: check if values are actually changed in layer.Biases
-				biasData.Data[ii] = biasData.Data[ii] - dBiasesValues[startI+ii]*learningRate
-			}
-			layer.Biases[bI].SetRawMatrix(biasData)
+// flattens Kernels into matrix where every row contains all values of one kernel
+// rows -> convolution depths; cols -> InputDepths * KernelSize * KernelSize
+func (layer *ConvolutionLayer) kernelsMatrix() *mat.Dense {
+	kernelSize := layer.KernelSize * layer.KernelSize
+	result := mat.NewDense(layer.KernelShape.Depths, layer.KernelShape.InputDepths*kernelSize, nil)
+	for i := 0; i < layer.KernelShape.Depths; i++ {
+		row := result.RawRowView(i)
+		for j := 0; j < layer.KernelShape.InputDepths; j++ {
+			kernel := mat.DenseCopyOf(&layer.Kernels[i][j])
+			copy(row[j*kernelSize:(j+1)*kernelSize], kernel.RawMatrix().Data)
+		}
+	}
+	return result
+}
+
+// every kernel and bias matrix is exposed as a separate parameter
+func (layer *ConvolutionLayer) Parameters() []Parameter {
+	// gradients are not available before the first Backward pass
+	if layer.DKernels == nil {
+		layer.DKernels = zerosLikeKernels(layer.Kernels)
+		layer.DBiases = zerosLikeBiases(layer.Biases)
+	}
+
+	parameters := make([]Parameter, 0, layer.KernelShape.Depths*layer.KernelShape.InputDepths+len(layer.Biases))
+	for i := 0; i < len(layer.Kernels); i++ {
+		for j := 0; j < len(layer.Kernels[i]); j++ {
+			name := fmt.Sprintf("kernels.%d.%d", i, j)
+			parameters = append(parameters, Parameter{
+				Name:      name,
+				Values:    &layer.Kernels[i][j],
+				Gradients: &layer.DKernels[i][j],
+				State:     layer.OptimizerState.For(name),
+			})
 		}
 	}
+	for i := 0; i < len(layer.Biases); i++ {
+		name := fmt.Sprintf("biases.%d", i)
+		parameters = append(parameters, Parameter{
+			Name:      name,
+			Values:    &layer.Biases[i],
+			Gradients: &layer.DBiases[i],
+			State:     layer.OptimizerState.For(name),
+		})
+	}
+	return parameters
 }
 
 func (layer *ConvolutionLayer) Name() string {
@@ -365,3 +535,22 @@ func (layer *ConvolutionLayer) AllBiases() []float64 {
 	}
 	return all
 }
+
+// allocates zero matrices with the same structure as Kernels
+func zerosLikeKernels(kernels [][]mat.Dense) [][]mat.Dense {
+	result := make([][]mat.Dense, len(kernels))
+	for i := 0; i < len(kernels); i++ {
+		result[i] = zerosLikeBiases(kernels[i])
+	}
+	return result
+}
+
+// allocates zero matrices with the same structure as Biases
+func zerosLikeBiases(biases []mat.Dense) []mat.Dense {
+	result := make([]mat.Dense, len(biases))
+	for i := 0; i < len(biases); i++ {
+		r, c := biases[i].Dims()
+		result[i] = *mat.NewDense(r, c, nil)
+	}
+	return result
+}
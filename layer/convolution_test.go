@@ -3,6 +3,7 @@ package layer_test
 import (
 	"fmt"
 	"main/layer"
+	"math"
 	"testing"
 
 	"gonum.org/v1/gonum/mat"
@@ -29,6 +30,39 @@ func TestConvolutionInit(t *testing.T) {
 	}
 }
 
+func TestConvolutionInitWithOptions(t *testing.T) {
+	l := layer.ConvolutionLayer{}
+	l.InitializationWithOptions(layer.InputShape{1, 28, 28}, 4, 3, layer.ConvolutionOptions{Stride: 1, Padding: layer.PaddingSame})
+
+	if l.Padding != 1 || l.OutputShape.Height != 28 || l.OutputShape.Width != 28 {
+		t.Fatalf("Incorrect same padding OutputShape: %v", l.OutputShape)
+	}
+
+	l = layer.ConvolutionLayer{}
+	l.InitializationWithOptions(layer.InputShape{1, 28, 28}, 4, 5, layer.ConvolutionOptions{Stride: 2, Padding: 1})
+
+	if l.OutputShape.Depths != 4 || l.OutputShape.Height != 13 || l.OutputShape.Width != 13 {
+		t.Fatalf("Incorrect strided OutputShape: %v", l.OutputShape)
+	}
+	if len(l.Biases) != 4 || l.Biases[0].RawMatrix().Rows != 13 {
+		t.Fatal("Incorrect Biases allocation")
+	}
+
+	inputData := mat.NewDense(2, 28*28, nil)
+	l.Forward(inputData, true)
+	if _, c := l.Output.Dims(); c != l.OutputShape.TotalSize() {
+		t.Fatal("Incorrect output")
+	}
+
+	l.Backward(mat.DenseCopyOf(&l.Output))
+	if _, c := l.DInputs.Dims(); c != l.InputShape.TotalSize() {
+		t.Fatal("Incorrect DInputs")
+	}
+	if r, c := l.DKernels[0][0].Dims(); r != 5 || c != 5 {
+		t.Fatal("Incorrect DKernels")
+	}
+}
+
 func TestConvolutionForwardPass(t *testing.T) {
 	l := layer.ConvolutionLayer{}
 	l.Initialization(layer.InputShape{1, 10, 10}, 2, 3)
@@ -59,3 +93,98 @@ func TestConvolutionForwardPass(t *testing.T) {
 
 	// TODO: generate input data
 }
+
+func TestConvolutionIm2ColMatchesDirect(t *testing.T) {
+	inputShape := layer.InputShape{Depths: 2, Height: 9, Width: 7}
+	for _, options := range []layer.ConvolutionOptions{
+		{Stride: 1, Padding: layer.PaddingValid},
+		{Stride: 1, Padding: layer.PaddingSame},
+		{Stride: 2, Padding: 1},
+		{Stride: 3, Padding: 2},
+	} {
+		options.Algorithm = layer.ConvolutionDirect
+		direct := layer.ConvolutionLayer{}
+		direct.InitializationWithOptions(inputShape, 3, 3, options)
+
+		im2col := direct
+		im2col.Algorithm = layer.ConvolutionIm2Col
+
+		inputData := mat.NewDense(4, inputShape.TotalSize(), nil)
+		for i := range inputData.RawMatrix().Data {
+			inputData.RawMatrix().Data[i] = math.Sin(float64(i))
+		}
+		direct.Forward(inputData, true)
+		im2col.Forward(inputData, true)
+
+		if !isClose(direct.Output.RawMatrix().Data, im2col.Output.RawMatrix().Data) {
+			t.Fatalf("Output mismatch with options: %v", options)
+		}
+
+		dvalues := mat.DenseCopyOf(&direct.Output)
+		dvalues.Apply(func(i, j int, v float64) float64 {
+			return math.Cos(v)
+		}, dvalues)
+		direct.Backward(dvalues)
+		im2col.Backward(dvalues)
+
+		if !isClose(direct.DInputs.RawMatrix().Data, im2col.DInputs.RawMatrix().Data) {
+			t.Fatalf("DInputs mismatch with options: %v", options)
+		}
+		for i := range direct.DKernels {
+			for j := range direct.DKernels[i] {
+				if !isClose(direct.DKernels[i][j].RawMatrix().Data, im2col.DKernels[i][j].RawMatrix().Data) {
+					t.Fatalf("DKernels mismatch with options: %v", options)
+				}
+			}
+		}
+		for i := range direct.DBiases {
+			if !isClose(direct.DBiases[i].RawMatrix().Data, im2col.DBiases[i].RawMatrix().Data) {
+				t.Fatalf("DBiases mismatch with options: %v", options)
+			}
+		}
+	}
+}
+
+func isClose(l []float64, r []float64) bool {
+	if len(l) != len(r) {
+		return false
+	}
+
+	for i := 0; i < len(l); i++ {
+		if math.Abs(l[i]-r[i]) > 1e-9 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// the second convolution of createCNNBigModel with a batch of 32 images
+func benchmarkConvolution(b *testing.B, algorithm layer.ConvolutionAlgorithm) {
+	inputShape := layer.InputShape{Depths: 32, Height: 13, Width: 13}
+	l := layer.ConvolutionLayer{}
+	l.InitializationWithOptions(inputShape, 64, 3, layer.ConvolutionOptions{Algorithm: algorithm})
+
+	inputData := mat.NewDense(32, inputShape.TotalSize(), nil)
+	for i := range inputData.RawMatrix().Data {
+		inputData.RawMatrix().Data[i] = math.Sin(float64(i))
+	}
+	dvalues := mat.NewDense(32, l.OutputShape.TotalSize(), nil)
+	for i := range dvalues.RawMatrix().Data {
+		dvalues.RawMatrix().Data[i] = math.Cos(float64(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Forward(inputData, true)
+		l.Backward(dvalues)
+	}
+}
+
+func BenchmarkConvolutionDirect(b *testing.B) {
+	benchmarkConvolution(b, layer.ConvolutionDirect)
+}
+
+func BenchmarkConvolutionIm2Col(b *testing.B) {
+	benchmarkConvolution(b, layer.ConvolutionIm2Col)
+}
@@ -33,13 +33,8 @@ type DenseLayer struct {
 	L1 Regularizer
 	L2 Regularizer
 
-	// used by SGD and Adam optimizer
-	WeightMomentums *mat.Dense
-	BiasMomentums   *mat.Dense
-
-	// used by Ada, Adam and RMSProp optimizers
-	WeightCache *mat.Dense
-	BiasCache   *mat.Dense
+	// state of optimizer for Weights and Biases
+	OptimizerState OptimizerState
 }
 
 func (layer *DenseLayer) Name() string {
@@ -153,6 +148,27 @@ func (layer *DenseLayer) Backward(dvalues *mat.Dense) {
 	layer.DInputs.Product(dvalues, layer.Weights.T())
 }
 
+func (layer *DenseLayer) Parameters() []Parameter {
+	return []Parameter{
+		{
+			Name:      "weights",
+			Values:    &layer.Weights,
+			Gradients: &layer.DWeights,
+			L1:        layer.L1.Weight,
+			L2:        layer.L2.Weight,
+			State:     layer.OptimizerState.For("weights"),
+		},
+		{
+			Name:      "biases",
+			Values:    &layer.Biases,
+			Gradients: &layer.DBiases,
+			L1:        layer.L1.Bias,
+			L2:        layer.L2.Bias,
+			State:     layer.OptimizerState.For("biases"),
+		},
+	}
+}
+
 func (a *DenseLayer) GetOutput() *mat.Dense {
 	return &a.Output
 }
@@ -1,6 +1,8 @@
 package layer
 
 import (
+	"log"
+
 	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/stat/distuv"
@@ -16,6 +18,9 @@ type DropoutLayer struct {
 
 	// dropout rate
 	Rate float64
+
+	// source of binary masks
+	source rand.Source
 }
 
 func (layer *DropoutLayer) Name() string {
@@ -23,6 +28,10 @@ func (layer *DropoutLayer) Name() string {
 }
 
 func (layer *DropoutLayer) Initialization(rate float64) *DropoutLayer {
+	// rate 1 drops everything, and kept values would be divided by zero
+	if rate < 0 || rate >= 1 {
+		log.Fatalf("Unexpected dropout rate: %v", rate)
+	}
 	layer.Rate = 1.0 - rate
 	return layer
 }
@@ -37,7 +46,12 @@ func (layer *DropoutLayer) Forward(inputs *mat.Dense, isTraining bool) {
 	}
 
 	layer.binaryMask = *mat.DenseCopyOf(inputs)
-	src := rand.New(rand.NewSource(1))
+	// the source keeps its state between passes, so every batch gets a new mask
+	// re-seeding it here would repeat the first mask at every step
+	if layer.source == nil {
+		layer.source = rand.NewSource(1)
+	}
+	src := rand.New(layer.source)
 	layer.binaryMask.Apply(func(i, j int, v float64) float64 {
 		return distuv.Binomial{N: 1, P: layer.Rate, Src: src}.Rand() / layer.Rate
 	}, &layer.binaryMask)
@@ -46,6 +60,10 @@ func (layer *DropoutLayer) Forward(inputs *mat.Dense, isTraining bool) {
 	layer.Output.MulElem(inputs, &layer.binaryMask)
 }
 
+func (layer *DropoutLayer) SetRandomSource(source rand.Source) {
+	layer.source = source
+}
+
 func (layer *DropoutLayer) Backward(dvalues *mat.Dense) {
 	layer.DInputs = *mat.DenseCopyOf(dvalues)
 	layer.DInputs.MulElem(dvalues, &layer.binaryMask)
@@ -0,0 +1,55 @@
+package layer_test
+
+import (
+	"main/layer"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestDropoutInference(t *testing.T) {
+	l := layer.DropoutLayer{}
+	l.Initialization(0.5)
+
+	inputData := mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	l.Forward(inputData, false)
+
+	if !mat.Equal(inputData, &l.Output) {
+		t.Fatal("Dropout has to pass inputs through during inference")
+	}
+}
+
+func TestDropoutMaskChangesBetweenBatches(t *testing.T) {
+	l := layer.DropoutLayer{}
+	l.Initialization(0.5)
+	l.SetRandomSource(rand.NewSource(42))
+
+	inputData := mat.NewDense(4, 8, nil)
+	inputData.Apply(func(i, j int, v float64) float64 { return 1.0 }, inputData)
+
+	l.Forward(inputData, true)
+	first := mat.DenseCopyOf(&l.Output)
+	l.Forward(inputData, true)
+
+	if mat.Equal(first, &l.Output) {
+		t.Fatal("Dropout has to use a new mask for every batch")
+	}
+}
+
+func TestDropoutConsecutiveForwardMasksDiffer(t *testing.T) {
+	// default source, as used by layers outside of a model
+	l := layer.DropoutLayer{}
+	l.Initialization(0.5)
+
+	inputData := mat.NewDense(4, 8, nil)
+	inputData.Apply(func(i, j int, v float64) float64 { return 1.0 }, inputData)
+
+	l.Forward(inputData, true)
+	first := mat.DenseCopyOf(&l.Output)
+	l.Forward(inputData, true)
+
+	if mat.Equal(first, &l.Output) {
+		t.Fatal("Consecutive Forward passes have to use different masks")
+	}
+}
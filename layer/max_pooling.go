@@ -66,7 +66,7 @@ func (layer *MaxPoolingLayer) Forward(inputs *mat.Dense, isTraining bool) {
 	for k := 0; k < inputSampleCount; k++ {
 		wg.Add(1)
 		go func(layer *MaxPoolingLayer, m *sync.Mutex, k int) {
-			wg.Done()
+			defer wg.Done()
 			inputSample := ConvertSampleData(layer.inputs.RawRowView(k), layer.InputShape)
 			outputSample := make([]float64, layer.OutputShape.TotalSize())
 
@@ -83,7 +83,7 @@ func (layer *MaxPoolingLayer) Forward(inputs *mat.Dense, isTraining bool) {
 						patch := inputSample[c].Slice(startI, endI, startJ, endJ)
 						value := MaxValue(patch)
 
-						idx := c*layer.OutputShape.Width*layer.OutputShape.Height + i*layer.OutputShape.Height + j
+						idx := c*layer.OutputShape.Width*layer.OutputShape.Height + i*layer.OutputShape.Width + j
 						outputSample[idx] = value
 					}
 				}
@@ -138,7 +138,7 @@ func (layer *MaxPoolingLayer) Backward(dvalues *mat.Dense) {
 						// set dinputs based on input dvalues and calculated mask
 						for ii := startI; ii < endI; ii++ {
 							for jj := startJ; jj < endJ; jj++ {
-								idx := c*layer.InputShape.Height*layer.InputShape.Width + ii*layer.InputShape.Height + jj
+								idx := c*layer.InputShape.Height*layer.InputShape.Width + ii*layer.InputShape.Width + jj
 								outputDInput[idx] = dvalueSample[c].At(i, j) * mask.At(ii-startI, jj-startJ)
 							}
 						}
@@ -0,0 +1,56 @@
+package layer_test
+
+import (
+	"main/layer"
+	"runtime"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestMaxPoolingForwardWaitsForAllSamples(t *testing.T) {
+	// samples are pooled in parallel goroutines
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	shape := layer.InputShape{Depths: 8, Height: 28, Width: 28}
+	l := (&layer.MaxPoolingLayer{}).Initialization(shape, 2)
+
+	// every value of sample k is k + 1, so every pooled value is k + 1 as well
+	samples := 64
+	inputData := mat.NewDense(samples, shape.TotalSize(), nil)
+	inputData.Apply(func(i, j int, v float64) float64 { return float64(i + 1) }, inputData)
+
+	l.Forward(inputData, false)
+	for k := 0; k < samples; k++ {
+		for _, v := range l.Output.RawRowView(k) {
+			if v != float64(k+1) {
+				t.Fatalf("Sample %v is not pooled: %v", k, l.Output.RawRowView(k))
+			}
+		}
+	}
+}
+
+func TestMaxPoolingNonSquareInput(t *testing.T) {
+	shape := layer.InputShape{Depths: 1, Height: 4, Width: 6}
+	l := (&layer.MaxPoolingLayer{}).Initialization(shape, 2)
+
+	// values grow along rows, so maximum of every patch is its bottom right value
+	inputData := mat.NewDense(1, shape.TotalSize(), nil)
+	inputData.Apply(func(i, j int, v float64) float64 { return float64(j) }, inputData)
+	l.Forward(inputData, false)
+
+	expected := []float64{7, 9, 11, 19, 21, 23}
+	if !isClose(l.Output.RawMatrix().Data, expected) {
+		t.Fatalf("Unexpected output: %v", l.Output.RawMatrix().Data)
+	}
+
+	l.Backward(mat.NewDense(1, 6, []float64{1, 2, 3, 4, 5, 6}))
+	expectedDInputs := []float64{
+		0, 0, 0, 0, 0, 0,
+		0, 1, 0, 2, 0, 3,
+		0, 0, 0, 0, 0, 0,
+		0, 4, 0, 5, 0, 6,
+	}
+	if !isClose(l.DInputs.RawMatrix().Data, expectedDInputs) {
+		t.Fatalf("Unexpected input gradients: %v", l.DInputs.RawMatrix().Data)
+	}
+}
@@ -0,0 +1,57 @@
+package layer
+
+import "gonum.org/v1/gonum/mat"
+
+// Parameter describes one trainable tensor of a layer
+// Values and Gradients point directly into the layer, so optimizers can update them in place
+type Parameter struct {
+	// name of the parameter, unique within the layer. E.g., "weights" or "kernels.0.1"
+	Name      string
+	Values    *mat.Dense
+	Gradients *mat.Dense
+
+	// regularization strength applied to Values
+	L1 float64
+	L2 float64
+
+	// optimizer state slots (momentums, cache, etc.) of this parameter
+	State ParameterState
+}
+
+// ParameterState holds optimizer state slots by slot name
+type ParameterState map[string]*mat.Dense
+
+// OptimizerState holds ParameterState by parameter name
+// layers keep it to preserve optimizer state between training steps
+type OptimizerState map[string]ParameterState
+
+// TrainableLayer is a layer with parameters adjusted by optimizers
+type TrainableLayer interface {
+	LayerInterface
+	Parameters() []Parameter
+}
+
+// returns state slot with a given name
+// slot is created with zero values and shape of Values if it does not exist yet
+func (parameter Parameter) Slot(name string) *mat.Dense {
+	value, ok := parameter.State[name]
+	if !ok {
+		r, c := parameter.Values.Dims()
+		value = mat.NewDense(r, c, nil)
+		parameter.State[name] = value
+	}
+	return value
+}
+
+// returns state of a parameter with a given name; state is created if it does not exist yet
+func (state *OptimizerState) For(name string) ParameterState {
+	if *state == nil {
+		*state = OptimizerState{}
+	}
+	value, ok := (*state)[name]
+	if !ok {
+		value = ParameterState{}
+		(*state)[name] = value
+	}
+	return value
+}
@@ -9,13 +9,13 @@ import (
 )
 
 type BaseLoss struct {
-	DInputs            mat.Dense
-	layers             []*layer.DenseLayer
+	DInputs            mat.Dense `json:"-"`
+	layers             []layer.TrainableLayer
 	accumulatedLossSum float64
 	accumulatedCount   int64
 }
 
-func (loss *BaseLoss) SetLayers(layers []*layer.DenseLayer) {
+func (loss *BaseLoss) SetLayers(layers []layer.TrainableLayer) {
 	loss.layers = layers
 }
 
@@ -56,34 +56,22 @@ func (loss *BaseLoss) RegularizationLoss() float64 {
 	value := 0.0
 
 	for _, layer := range loss.layers {
-		if layer.L1.Weight > 0 {
-			temp := 0.0
-			for i := range layer.Weights.RawMatrix().Data {
-				temp += math.Abs(layer.Weights.RawMatrix().Data[i])
+		for _, parameter := range layer.Parameters() {
+			if parameter.L1 > 0 {
+				temp := 0.0
+				for _, v := range parameter.Values.RawMatrix().Data {
+					temp += math.Abs(v)
+				}
+				value += parameter.L1 * temp
 			}
-			value += layer.L1.Weight * temp
-		}
-		if layer.L1.Bias > 0 {
-			temp := 0.0
-			for i := range layer.Biases.RawMatrix().Data {
-				temp += math.Abs(layer.Biases.RawMatrix().Data[i])
-			}
-			value += layer.L1.Bias * temp
-		}
 
-		if layer.L2.Weight > 0 {
-			temp := 0.0
-			for i := range layer.Weights.RawMatrix().Data {
-				temp += math.Pow(layer.Weights.RawMatrix().Data[i], 2)
-			}
-			value += layer.L2.Weight * temp
-		}
-		if layer.L2.Bias > 0 {
-			temp := 0.0
-			for i := range layer.Biases.RawMatrix().Data {
-				temp += math.Pow(layer.Biases.RawMatrix().Data[i], 2)
+			if parameter.L2 > 0 {
+				temp := 0.0
+				for _, v := range parameter.Values.RawMatrix().Data {
+					temp += math.Pow(v, 2)
+				}
+				value += parameter.L2 * temp
 			}
-			value += layer.L2.Bias * temp
 		}
 	}
 	return value
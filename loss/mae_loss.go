@@ -41,7 +41,7 @@ func (loss *MeanAbsoluteErrorLoss) Backward(dvalues *mat.Dense, target *mat.Dens
 
 	loss.DInputs = *mat.DenseCopyOf(dvalues)
 	loss.DInputs.Apply(func(i, j int, v float64) float64 {
-		return sign(target.At(i, j)-dvalues.At(i, j)) / float64(outputCount) / float64(sampleCount)
+		return sign(dvalues.At(i, j)-target.At(i, j)) / float64(outputCount) / float64(sampleCount)
 	}, &loss.DInputs)
 }
 
@@ -54,3 +54,7 @@ func sign(value float64) float64 {
 		return 1
 	}
 }
+
+func (loss *MeanAbsoluteErrorLoss) GetDInputs() *mat.Dense {
+	return &loss.DInputs
+}
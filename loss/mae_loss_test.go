@@ -0,0 +1,21 @@
+package loss_test
+
+import (
+	"main/loss"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestMeanAbsoluteErrorBackward(t *testing.T) {
+	l := loss.MeanAbsoluteErrorLoss{}
+	predictions := mat.NewDense(2, 2, []float64{1, 3, 2, 2})
+	targets := mat.NewDense(2, 2, []float64{2, 1, 2, 0})
+	l.Backward(predictions, targets)
+
+	// loss grows when predictions move away from targets: sign(prediction - target) / outputs / samples
+	expected := mat.NewDense(2, 2, []float64{-0.25, 0.25, 0, 0.25})
+	if !mat.Equal(expected, &l.DInputs) {
+		t.Fatalf("Unexpected gradients:\n%v", mat.Formatted(&l.DInputs))
+	}
+}
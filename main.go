@@ -29,6 +29,7 @@ func testModelV1() {
 	validationData := model.ModelData{X: *x_val, Y: *y_val}
 	batchSize := 128
 
+	loadedModel.Reporter = model.PrintReporter{}
 	loadedModel.Evaluate(validationData, &batchSize)
 	_, c := x_val.Dims()
 	testX := mat.NewDense(1, c, x_val.RawRowView(0))
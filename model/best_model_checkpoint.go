@@ -0,0 +1,49 @@
+package model
+
+import (
+	"log"
+	"math"
+)
+
+// BestModelCheckpoint stores the model every time its loss on validation data improves
+type BestModelCheckpoint struct {
+	BaseCallback
+
+	// data evaluated after every epoch; optional if Train validates every epoch itself
+	// epochs without validation are skipped
+	ValidationData ModelData
+	BatchSize      *int
+
+	Path string
+	// provider used to store the model; chosen by extension of Path if nil
+	Provider ModelDataProvider
+
+	BestLoss  float64
+	BestEpoch int
+}
+
+func (c *BestModelCheckpoint) OnTrainBegin(m *Model) {
+	c.BestLoss = math.Inf(1)
+	c.BestEpoch = -1
+}
+
+func (c *BestModelCheckpoint) OnEpochEnd(m *Model, record EpochRecord) {
+	loss, ok := validationLoss(m, record, c.ValidationData, c.BatchSize)
+	if !ok {
+		return
+	}
+	if loss >= c.BestLoss {
+		return
+	}
+	c.BestLoss = loss
+	c.BestEpoch = record.Epoch
+
+	provider := c.Provider
+	if provider == nil {
+		provider = ProviderForPath(c.Path)
+	}
+	err := provider.Store(c.Path, m)
+	if err != nil {
+		log.Fatalf("Failed to store the best model: %v", err)
+	}
+}
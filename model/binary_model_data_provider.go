@@ -0,0 +1,240 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"main/model/marshaling"
+	"math"
+	"os"
+	"path/filepath"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Binary model file layout, all numbers are little-endian:
+//
+//	magic       [4]byte "NNFS"
+//	version     uint16
+//	metadata    uint32 length + JSON document of JSONModelDataProvider with tensor references instead of values
+//	tensors     uint32 count, then for every tensor:
+//	            uint8 element size (4 for float32, 8 for float64), uint32 rows, uint32 cols, rows*cols elements
+//	checksum    uint32 CRC-32 (IEEE) of all previous bytes
+const (
+	binaryModelMagic   = "NNFS"
+	binaryModelVersion = 1
+)
+
+type BinaryModelDataProvider struct {
+	// stores tensors as float32; halves the file size but loses precision
+	Float32 bool
+}
+
+func (provider *BinaryModelDataProvider) Store(path string, model *Model) error {
+	return writeBinaryDocument(path, provider.Float32, func(tensors *marshaling.TensorTable) ([]byte, error) {
+		return encodeModel(model, tensors)
+	})
+}
+
+func (provider *BinaryModelDataProvider) Load(path string) (*Model, error) {
+	var m *Model
+	err := readBinaryDocument(path, func(document []byte, tensors *marshaling.TensorTable) error {
+		var err error
+		m, err = decodeModel(document, tensors)
+		return err
+	})
+	return m, err
+}
+
+// writes JSON document produced by encode with its tensors stored as raw data
+func writeBinaryDocument(path string, asFloat32 bool, encode func(tensors *marshaling.TensorTable) ([]byte, error)) error {
+	table := marshaling.TensorTable{}
+	metadata, err := encode(&table)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(path, func(file io.Writer) error {
+		checksum := crc32.NewIEEE()
+		buffer := bufio.NewWriter(io.MultiWriter(file, checksum))
+		w := binaryWriter{w: buffer}
+
+		w.write([]byte(binaryModelMagic))
+		w.write(uint16(binaryModelVersion))
+		w.write(uint32(len(metadata)))
+		w.write(metadata)
+
+		w.write(uint32(len(table.Tensors)))
+		for i := range table.Tensors {
+			w.writeTensor(&table.Tensors[i], asFloat32)
+		}
+		if w.err != nil {
+			return w.err
+		}
+
+		err := buffer.Flush()
+		if err != nil {
+			return err
+		}
+		return binary.Write(file, binary.LittleEndian, checksum.Sum32())
+	})
+}
+
+// reads document written by writeBinaryDocument and passes it to decode with its tensors
+func readBinaryDocument(path string, decode func(document []byte, tensors *marshaling.TensorTable) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if len(data) < len(binaryModelMagic)+4 || string(data[:len(binaryModelMagic)]) != binaryModelMagic {
+		return errors.New("not a binary model file")
+	}
+	content, stored := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(content) != stored {
+		return errors.New("checksum mismatch")
+	}
+
+	r := binaryReader{r: bytes.NewReader(content[len(binaryModelMagic):])}
+	var version uint16
+	r.read(&version)
+	if r.err == nil && version != binaryModelVersion {
+		return fmt.Errorf("unsupported binary model version: %v", version)
+	}
+
+	var metadataLength uint32
+	r.read(&metadataLength)
+	metadata := r.bytes(int(metadataLength))
+
+	var count uint32
+	r.read(&count)
+	table := marshaling.TensorTable{}
+	for i := 0; i < int(count) && r.err == nil; i++ {
+		table.Tensors = append(table.Tensors, r.readTensor())
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	return decode(metadata, &table)
+}
+
+// ProviderForPath returns provider by file extension: JSON for .json, binary otherwise
+func ProviderForPath(path string) ModelDataProvider {
+	if filepath.Ext(path) == ".json" {
+		return &JSONModelDataProvider{}
+	}
+	return &BinaryModelDataProvider{}
+}
+
+// ConvertModel loads model stored at src and stores it at dst
+func ConvertModel(src string, srcProvider ModelDataProvider, dst string, dstProvider ModelDataProvider) error {
+	m, err := srcProvider.Load(src)
+	if err != nil {
+		return err
+	}
+	return dstProvider.Store(dst, m)
+}
+
+// binaryWriter keeps the first error, so writes can be chained without checks
+type binaryWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *binaryWriter) write(value any) {
+	if w.err == nil {
+		w.err = binary.Write(w.w, binary.LittleEndian, value)
+	}
+}
+
+func (w *binaryWriter) writeTensor(tensor *mat.Dense, asFloat32 bool) {
+	rows, cols := 0, 0
+	var values []float64
+	if !tensor.IsEmpty() {
+		rows, cols = tensor.Dims()
+		values = mat.DenseCopyOf(tensor).RawMatrix().Data
+	}
+
+	if asFloat32 {
+		w.write(uint8(4))
+	} else {
+		w.write(uint8(8))
+	}
+	w.write(uint32(rows))
+	w.write(uint32(cols))
+
+	buffer := make([]byte, 0, 8*len(values))
+	for _, v := range values {
+		if asFloat32 {
+			buffer = binary.LittleEndian.AppendUint32(buffer, math.Float32bits(float32(v)))
+		} else {
+			buffer = binary.LittleEndian.AppendUint64(buffer, math.Float64bits(v))
+		}
+	}
+	w.write(buffer)
+}
+
+type binaryReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (r *binaryReader) read(value any) {
+	if r.err == nil {
+		r.err = binary.Read(r.r, binary.LittleEndian, value)
+	}
+}
+
+func (r *binaryReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > r.r.Len() {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	data := make([]byte, n)
+	_, r.err = io.ReadFull(r.r, data)
+	return data
+}
+
+func (r *binaryReader) readTensor() mat.Dense {
+	var size uint8
+	var rows, cols uint32
+	r.read(&size)
+	r.read(&rows)
+	r.read(&cols)
+	if r.err != nil {
+		return mat.Dense{}
+	}
+	if size != 4 && size != 8 {
+		r.err = fmt.Errorf("unsupported tensor element size: %v", size)
+		return mat.Dense{}
+	}
+
+	// sizes come from the file, so they are bounded by bytes that are left before allocating values
+	if uint64(rows)*uint64(cols) > uint64(r.r.Len())/uint64(size) {
+		r.err = fmt.Errorf("tensor of %vx%v values exceeds the file", rows, cols)
+		return mat.Dense{}
+	}
+	count := int(rows) * int(cols)
+	data := r.bytes(count * int(size))
+	if r.err != nil || count == 0 {
+		return mat.Dense{}
+	}
+
+	values := make([]float64, count)
+	for i := range values {
+		if size == 4 {
+			values[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:])))
+		} else {
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+		}
+	}
+	return *mat.NewDense(int(rows), int(cols), values)
+}
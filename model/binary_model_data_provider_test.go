@@ -0,0 +1,170 @@
+package model_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"main/layer"
+	"main/model"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestBinaryModelDataProviderRoundTrip(t *testing.T) {
+	jsonProvider := model.JSONModelDataProvider{}
+	m, err := jsonProvider.Load("../assets/fashion-cnn-max-pooling.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, asFloat32 := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "model.bin")
+		provider := model.BinaryModelDataProvider{Float32: asFloat32}
+		err = provider.Store(path, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := provider.Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tolerance := 0.0
+		if asFloat32 {
+			tolerance = 1e-6
+		}
+		for i, l := range m.Layers {
+			trainable, ok := l.(layer.TrainableLayer)
+			if !ok {
+				continue
+			}
+			loadedParameters := loaded.Layers[i].(layer.TrainableLayer).Parameters()
+			for j, parameter := range trainable.Parameters() {
+				if !mat.EqualApprox(parameter.Values, loadedParameters[j].Values, tolerance) {
+					t.Fatalf("float32 %v: parameter %v of layer %v is not restored", asFloat32, parameter.Name, i)
+				}
+			}
+		}
+	}
+}
+
+// tensor tables of binary files must not leak into JSON files stored at the same time
+func TestConcurrentJSONAndBinaryStore(t *testing.T) {
+	m, err := (&model.JSONModelDataProvider{}).Load("../assets/fashion-dense.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	wg := sync.WaitGroup{}
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path := filepath.Join(dir, fmt.Sprintf("model-%d.json", i))
+			if i%2 == 1 {
+				path = filepath.Join(dir, fmt.Sprintf("model-%d.nnfs", i))
+			}
+			errs[i] = model.ProviderForPath(path).Store(path, m)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i%2 == 1 {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("model-%d.json", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), `"tensor"`) {
+			t.Fatal("JSON file contains tensor references")
+		}
+	}
+}
+
+func TestConvertModel(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := "../assets/fashion-dense.json"
+	binaryPath := filepath.Join(dir, "model.bin")
+	err := model.ConvertModel(jsonPath, model.ProviderForPath(jsonPath), binaryPath, model.ProviderForPath(binaryPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonInfo, err := os.Stat(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binaryInfo, err := os.Stat(binaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binaryInfo.Size()*2 > jsonInfo.Size() {
+		t.Fatalf("Binary model is too large: %v bytes, JSON: %v bytes", binaryInfo.Size(), jsonInfo.Size())
+	}
+
+	// and back
+	convertedPath := filepath.Join(dir, "model.json")
+	err = model.ConvertModel(binaryPath, model.ProviderForPath(binaryPath), convertedPath, model.ProviderForPath(convertedPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBinaryModelDataProviderCorrupted(t *testing.T) {
+	jsonPath := "../assets/fashion-dense.json"
+	path := filepath.Join(t.TempDir(), "model.bin")
+	provider := model.BinaryModelDataProvider{}
+	err := model.ConvertModel(jsonPath, model.ProviderForPath(jsonPath), path, &provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)/2] ^= 0xFF
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = provider.Load(path)
+	if err == nil {
+		t.Fatal("Expected checksum error")
+	}
+}
+
+func TestBinaryModelDataProviderTensorSizeExceedsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.bin")
+	// valid checksum, but sizes of the only tensor overflow int
+	content := []byte("NNFS")
+	content = binary.LittleEndian.AppendUint16(content, 1)
+	content = binary.LittleEndian.AppendUint32(content, 2)
+	content = append(content, "{}"...)
+	content = binary.LittleEndian.AppendUint32(content, 1)
+	content = append(content, 8)
+	content = binary.LittleEndian.AppendUint32(content, 0xffffffff)
+	content = binary.LittleEndian.AppendUint32(content, 0xffffffff)
+	content = binary.LittleEndian.AppendUint32(content, crc32.ChecksumIEEE(content))
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := model.BinaryModelDataProvider{}
+	if _, err := provider.Load(path); err == nil {
+		t.Fatal("Expected error for tensor larger than the file")
+	}
+}
@@ -0,0 +1,251 @@
+package model
+
+import (
+	"encoding/json"
+	"main/accuracy"
+	"main/activation"
+	"main/augmentation"
+	"main/layer"
+	"main/loss"
+	"main/model/marshaling"
+	"main/optimizations"
+	"main/optimizer"
+	"main/scaler"
+	"main/scheduler"
+)
+
+// registries used by JSONModelDataProvider
+// activations are layers and are registered in LayerRegistry
+var (
+	LayerRegistry     = NewRegistry[layer.LayerInterface]("layer")
+	LossRegistry      = NewRegistry[loss.LossInterface]("loss")
+	AccuracyRegistry  = NewRegistry[accuracy.AccuracyInterface]("accuracy")
+	OptimizerRegistry = NewRegistry[optimizer.OptimizerInterface]("optimizer")
+	ScalerRegistry    = NewRegistry[scaler.ScalerInterface]("scaler")
+	SchedulerRegistry = NewRegistry[scheduler.SchedulerInterface]("scheduler")
+	// transforms of augmentation.AugmentationLayer
+	TransformRegistry = NewRegistry[augmentation.Transform]("transform")
+)
+
+// aliases are type names used by files stored before the registries were introduced
+func init() {
+	registerLayers()
+	registerActivations()
+	registerAugmentation()
+	registerSchedulers()
+
+	LossRegistry.RegisterJSON("categorical_crossentropy", func() loss.LossInterface { return &loss.CategoricalCrossentropyLoss{} }, "*loss.CategoricalCrossentropyLoss")
+	LossRegistry.RegisterJSON("binary_crossentropy", func() loss.LossInterface { return &loss.BinaryCrossentropyLoss{} }, "*loss.BinaryCrossentropyLoss")
+	LossRegistry.RegisterJSON("mean_squared_error", func() loss.LossInterface { return &loss.MeanSquaredErrorLoss{} }, "*loss.MeanSquaredErrorLoss")
+	LossRegistry.RegisterJSON("mean_absolute_error", func() loss.LossInterface { return &loss.MeanAbsoluteErrorLoss{} }, "*loss.MeanAbsoluteErrorLoss")
+	LossRegistry.RegisterJSON("optimized_categorical_crossentropy", func() loss.LossInterface { return &optimizations.OptimizedCategoricalCrossentropyLoss{} }, "*optimizations.OptimizedCategoricalCrossentropyLoss")
+
+	AccuracyRegistry.RegisterJSON("categorical", func() accuracy.AccuracyInterface { return &accuracy.CategorialAccuracy{} }, "*accuracy.CategorialAccuracy")
+	AccuracyRegistry.RegisterJSON("binary_categorical", func() accuracy.AccuracyInterface { return &accuracy.BinaryCategorialAccuracy{} }, "*accuracy.BinaryCategorialAccuracy")
+	AccuracyRegistry.RegisterJSON("regression", func() accuracy.AccuracyInterface { return &accuracy.RegressionAccuracy{} }, "*accuracy.RegressionAccuracy")
+
+	OptimizerRegistry.RegisterJSON("sgd", func() optimizer.OptimizerInterface { return &optimizer.OptimizerSGD{} }, "*optimizer.OptimizerSGD")
+	OptimizerRegistry.RegisterJSON("adagrad", func() optimizer.OptimizerInterface { return &optimizer.OptimizerAda{} }, "*optimizer.OptimizerAda")
+	OptimizerRegistry.RegisterJSON("rmsprop", func() optimizer.OptimizerInterface { return &optimizer.OptimizerRMSprop{} }, "*optimizer.OptimizerRMSprop")
+	OptimizerRegistry.RegisterJSON("adam", func() optimizer.OptimizerInterface { return &optimizer.OptimizerAdam{} }, "*optimizer.OptimizerAdam")
+	OptimizerRegistry.RegisterJSON("adamw", func() optimizer.OptimizerInterface { return &optimizer.OptimizerAdamW{} })
+	OptimizerRegistry.RegisterJSON("nadam", func() optimizer.OptimizerInterface { return &optimizer.OptimizerNadam{} })
+	OptimizerRegistry.RegisterJSON("adamax", func() optimizer.OptimizerInterface { return &optimizer.OptimizerAdaMax{} })
+	OptimizerRegistry.RegisterJSON("adadelta", func() optimizer.OptimizerInterface { return &optimizer.OptimizerAdadelta{} })
+
+	ScalerRegistry.RegisterJSON("standard", func() scaler.ScalerInterface { return &scaler.StandardScaler{} })
+	ScalerRegistry.RegisterJSON("min_max", func() scaler.ScalerInterface { return &scaler.MinMaxScaler{} })
+	ScalerRegistry.RegisterJSON("robust", func() scaler.ScalerInterface { return &scaler.RobustScaler{} })
+}
+
+func registerLayers() {
+	LayerRegistry.Register("dense", func() layer.LayerInterface { return &layer.DenseLayer{} },
+		func(value layer.LayerInterface, tensors *marshaling.TensorTable) ([]byte, error) {
+			return encodeWrapper(marshaling.LayerWrapper{DenseLayer: *value.(*layer.DenseLayer), Tensors: tensors})
+		},
+		func(data []byte, tensors *marshaling.TensorTable) (layer.LayerInterface, error) {
+			wrap := marshaling.LayerWrapper{Tensors: tensors}
+			err := decodeWrapper(data, &wrap)
+			if err != nil {
+				return nil, err
+			}
+			l := layer.DenseLayer{}
+			l.LoadFromParams(&wrap.Weights, &wrap.Biases, wrap.L1, wrap.L2)
+			return &l, nil
+		},
+		"layer.DenseLayer")
+
+	LayerRegistry.Register("convolution", func() layer.LayerInterface { return &layer.ConvolutionLayer{} },
+		func(value layer.LayerInterface, tensors *marshaling.TensorTable) ([]byte, error) {
+			return encodeWrapper(marshaling.ConvolutionWrapper{ConvolutionLayer: *value.(*layer.ConvolutionLayer), Tensors: tensors})
+		},
+		func(data []byte, tensors *marshaling.TensorTable) (layer.LayerInterface, error) {
+			wrap := marshaling.ConvolutionWrapper{Tensors: tensors}
+			err := decodeWrapper(data, &wrap)
+			if err != nil {
+				return nil, err
+			}
+			l := layer.ConvolutionLayer{}
+			l.LoadFromParams(wrap.InputShape, wrap.Depths, wrap.KernelSize, wrap.Stride, wrap.Padding, wrap.OutputShape, wrap.KernelShape, wrap.Kernels, wrap.Biases)
+			return &l, nil
+		},
+		"layer.ConvolutionLayer")
+
+	LayerRegistry.Register("max_pooling", func() layer.LayerInterface { return &layer.MaxPoolingLayer{} },
+		func(value layer.LayerInterface, tensors *marshaling.TensorTable) ([]byte, error) {
+			return encodeWrapper(marshaling.MaxPoolingWrapper{MaxPoolingLayer: *value.(*layer.MaxPoolingLayer)})
+		},
+		func(data []byte, tensors *marshaling.TensorTable) (layer.LayerInterface, error) {
+			wrap := marshaling.MaxPoolingWrapper{}
+			err := decodeWrapper(data, &wrap)
+			if err != nil {
+				return nil, err
+			}
+			l := layer.MaxPoolingLayer{}
+			l.LoadFromParams(wrap.PoolSize, wrap.InputShape, wrap.OutputShape)
+			return &l, nil
+		},
+		"layer.MaxPoolingLayer")
+
+	LayerRegistry.Register("batch_norm", func() layer.LayerInterface { return &layer.BatchNormLayer{} },
+		func(value layer.LayerInterface, tensors *marshaling.TensorTable) ([]byte, error) {
+			return encodeWrapper(marshaling.BatchNormWrapper{BatchNormLayer: *value.(*layer.BatchNormLayer), Tensors: tensors})
+		},
+		func(data []byte, tensors *marshaling.TensorTable) (layer.LayerInterface, error) {
+			wrap := marshaling.BatchNormWrapper{Tensors: tensors}
+			err := decodeWrapper(data, &wrap)
+			if err != nil {
+				return nil, err
+			}
+			l := layer.BatchNormLayer{}
+			l.LoadFromParams(wrap.Channels, wrap.ChannelSize, wrap.Momentum, wrap.Epsilon, &wrap.Gamma, &wrap.Beta, &wrap.RunningMean, &wrap.RunningVariance)
+			return &l, nil
+		},
+		"layer.BatchNormLayer")
+
+	LayerRegistry.Register("dropout", func() layer.LayerInterface { return &layer.DropoutLayer{} },
+		func(value layer.LayerInterface, tensors *marshaling.TensorTable) ([]byte, error) {
+			return encodeWrapper(marshaling.DropoutWrapper{DropoutLayer: *value.(*layer.DropoutLayer)})
+		},
+		func(data []byte, tensors *marshaling.TensorTable) (layer.LayerInterface, error) {
+			wrap := marshaling.DropoutWrapper{}
+			err := decodeWrapper(data, &wrap)
+			return &wrap.DropoutLayer, err
+		},
+		"layer.DropoutLayer")
+}
+
+// activations don't have any data to store
+func registerActivations() {
+	LayerRegistry.Register("relu", func() layer.LayerInterface { return &activation.Activation_ReLU{} }, nil, nil, "*activation.Activation_ReLU")
+	LayerRegistry.Register("softmax", func() layer.LayerInterface { return &activation.SoftmaxActivation{} }, nil, nil, "*activation.SoftmaxActivation")
+	LayerRegistry.Register("sigmoid", func() layer.LayerInterface { return &activation.SigmoidActivation{} }, nil, nil, "*activation.SigmoidActivation")
+	LayerRegistry.Register("linear", func() layer.LayerInterface { return &activation.LinearActivation{} }, nil, nil, "*activation.LinearActivation")
+	LayerRegistry.Register("optimized_softmax", func() layer.LayerInterface { return &optimizations.OptimizedSoftmaxActivation{} }, nil, nil, "*optimizations.OptimizedSoftmaxActivation")
+}
+
+func registerSchedulers() {
+	SchedulerRegistry.RegisterJSON("inverse_time", func() scheduler.SchedulerInterface { return &scheduler.InverseTimeDecay{} })
+	SchedulerRegistry.RegisterJSON("step", func() scheduler.SchedulerInterface { return &scheduler.StepDecay{} })
+	SchedulerRegistry.RegisterJSON("exponential", func() scheduler.SchedulerInterface { return &scheduler.ExponentialDecay{} })
+	SchedulerRegistry.RegisterJSON("cosine_warm_restarts", func() scheduler.SchedulerInterface { return &scheduler.CosineWarmRestarts{} })
+	SchedulerRegistry.RegisterJSON("one_cycle", func() scheduler.SchedulerInterface { return &scheduler.OneCycle{} })
+	SchedulerRegistry.RegisterJSON("reduce_on_plateau", func() scheduler.SchedulerInterface { return &scheduler.ReduceOnPlateau{} })
+
+	// scheduler used after warmup is stored with its own type
+	type warmupData struct {
+		WarmupSteps int         `json:"warmup_steps"`
+		After       *typedValue `json:"after,omitempty"`
+	}
+	SchedulerRegistry.Register("linear_warmup", func() scheduler.SchedulerInterface { return &scheduler.LinearWarmup{} },
+		func(value scheduler.SchedulerInterface, tensors *marshaling.TensorTable) ([]byte, error) {
+			s := value.(*scheduler.LinearWarmup)
+			data := warmupData{WarmupSteps: s.WarmupSteps}
+			if s.After != nil {
+				after, err := SchedulerRegistry.encode(s.After, tensors)
+				if err != nil {
+					return nil, err
+				}
+				data.After = &after
+			}
+			return json.Marshal(data)
+		},
+		func(d []byte, tensors *marshaling.TensorTable) (scheduler.SchedulerInterface, error) {
+			data := warmupData{}
+			err := json.Unmarshal(d, &data)
+			if err != nil {
+				return nil, err
+			}
+			s := scheduler.LinearWarmup{WarmupSteps: data.WarmupSteps}
+			if data.After != nil {
+				s.After, err = SchedulerRegistry.decode(*data.After, tensors)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return &s, nil
+		})
+}
+
+func registerAugmentation() {
+	TransformRegistry.RegisterJSON("horizontal_flip", func() augmentation.Transform { return &augmentation.HorizontalFlip{} })
+	TransformRegistry.RegisterJSON("vertical_flip", func() augmentation.Transform { return &augmentation.VerticalFlip{} })
+	TransformRegistry.RegisterJSON("shift", func() augmentation.Transform { return &augmentation.Shift{} })
+	TransformRegistry.RegisterJSON("rotation", func() augmentation.Transform { return &augmentation.Rotation{} })
+	TransformRegistry.RegisterJSON("noise", func() augmentation.Transform { return &augmentation.Noise{} })
+	TransformRegistry.RegisterJSON("brightness", func() augmentation.Transform { return &augmentation.Brightness{} })
+
+	type augmentationData struct {
+		InputShape layer.InputShape `json:"input_shape"`
+		Transforms []typedValue     `json:"transforms"`
+	}
+	LayerRegistry.Register("augmentation", func() layer.LayerInterface { return &augmentation.AugmentationLayer{} },
+		func(value layer.LayerInterface, tensors *marshaling.TensorTable) ([]byte, error) {
+			l := value.(*augmentation.AugmentationLayer)
+			data := augmentationData{InputShape: l.InputShape, Transforms: []typedValue{}}
+			for _, transform := range l.Transforms {
+				value, err := TransformRegistry.encode(transform, tensors)
+				if err != nil {
+					return nil, err
+				}
+				data.Transforms = append(data.Transforms, value)
+			}
+			return json.Marshal(data)
+		},
+		func(d []byte, tensors *marshaling.TensorTable) (layer.LayerInterface, error) {
+			data := augmentationData{}
+			err := json.Unmarshal(d, &data)
+			if err != nil {
+				return nil, err
+			}
+			l := augmentation.AugmentationLayer{InputShape: data.InputShape}
+			for _, value := range data.Transforms {
+				transform, err := TransformRegistry.decode(value, tensors)
+				if err != nil {
+					return nil, err
+				}
+				l.Transforms = append(l.Transforms, transform)
+			}
+			return &l, nil
+		})
+}
+
+// marshaling wrappers store their own type; only their data is kept
+func encodeWrapper(wrapper json.Marshaler) ([]byte, error) {
+	data, err := wrapper.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	value := typedValue{}
+	err = json.Unmarshal(data, &value)
+	return value.Data, err
+}
+
+func decodeWrapper(data []byte, wrapper json.Unmarshaler) error {
+	d, err := json.Marshal(typedValue{Data: data})
+	if err != nil {
+		return err
+	}
+	return wrapper.UnmarshalJSON(d)
+}
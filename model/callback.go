@@ -0,0 +1,45 @@
+package model
+
+// Callback runs code at certain points of Model.Train
+// embed BaseCallback to implement only required methods
+type Callback interface {
+	OnTrainBegin(m *Model)
+	OnTrainEnd(m *Model, history *History)
+	OnEpochBegin(m *Model, epoch int)
+	OnEpochEnd(m *Model, record EpochRecord)
+	OnBatchBegin(m *Model, epoch int, step int)
+	OnBatchEnd(m *Model, record StepRecord)
+}
+
+// WithCallbacks adds callbacks to Model.Train; they are called in the given order
+func WithCallbacks(callbacks ...Callback) TrainOption {
+	return func(options *trainOptions) {
+		options.callbacks = append(options.callbacks, callbacks...)
+	}
+}
+
+// StopTraining makes Model.Train finish after the current epoch
+func (m *Model) StopTraining() {
+	m.stopTraining = true
+}
+
+type BaseCallback struct{}
+
+func (c *BaseCallback) OnTrainBegin(m *Model)                      {}
+func (c *BaseCallback) OnTrainEnd(m *Model, history *History)      {}
+func (c *BaseCallback) OnEpochBegin(m *Model, epoch int)           {}
+func (c *BaseCallback) OnEpochEnd(m *Model, record EpochRecord)    {}
+func (c *BaseCallback) OnBatchBegin(m *Model, epoch int, step int) {}
+func (c *BaseCallback) OnBatchEnd(m *Model, record StepRecord)     {}
+
+// returns validation loss of the epoch: evaluated by Train (see ValidateEvery) or on data of a callback
+// ok is false if the epoch wasn't validated and data is empty
+func validationLoss(m *Model, record EpochRecord, data ModelData, batchSize *int) (loss float64, ok bool) {
+	if record.Validation != nil {
+		return record.Validation.Loss, true
+	}
+	if data.X.IsEmpty() {
+		return 0, false
+	}
+	return m.Evaluate(data, batchSize).Loss, true
+}
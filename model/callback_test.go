@@ -0,0 +1,125 @@
+package model_test
+
+import (
+	"encoding/csv"
+	"fmt"
+	"main/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type recordingCallback struct {
+	model.BaseCallback
+	events []string
+}
+
+func (c *recordingCallback) OnTrainBegin(m *model.Model) {
+	c.events = append(c.events, "train begin")
+}
+
+func (c *recordingCallback) OnTrainEnd(m *model.Model, history *model.History) {
+	c.events = append(c.events, fmt.Sprintf("train end %v", len(history.Epochs)))
+}
+
+func (c *recordingCallback) OnEpochBegin(m *model.Model, epoch int) {
+	c.events = append(c.events, fmt.Sprintf("epoch begin %v", epoch))
+}
+
+func (c *recordingCallback) OnEpochEnd(m *model.Model, record model.EpochRecord) {
+	c.events = append(c.events, fmt.Sprintf("epoch end %v", record.Epoch))
+}
+
+func (c *recordingCallback) OnBatchBegin(m *model.Model, epoch int, step int) {
+	c.events = append(c.events, fmt.Sprintf("batch begin %v %v", epoch, step))
+}
+
+func (c *recordingCallback) OnBatchEnd(m *model.Model, record model.StepRecord) {
+	c.events = append(c.events, fmt.Sprintf("batch end %v %v", record.Epoch, record.Step))
+}
+
+func callbackTestData() model.ModelData {
+	return model.ModelData{
+		X: *mat.NewDense(8, 2, []float64{0.1, 0.2, 0.9, 0.8, 0.2, 0.1, 0.7, 0.9, 0.3, 0.2, 0.8, 0.7, 0.1, 0.3, 0.9, 0.9}),
+		Y: *mat.NewDense(8, 1, []float64{0, 1, 0, 1, 0, 1, 0, 1}),
+	}
+}
+
+func TestCallbackOrder(t *testing.T) {
+	batchSize := 5
+	callback := recordingCallback{}
+
+	m := newCheckpointTestModel()
+	m.Train(callbackTestData(), 1, &batchSize, 100, nil, model.WithCallbacks(&callback))
+
+	expected := []string{
+		"train begin",
+		"epoch begin 0", "batch begin 0 0", "batch end 0 0", "batch begin 0 1", "batch end 0 1", "epoch end 0",
+		"epoch begin 1", "batch begin 1 0", "batch end 1 0", "batch begin 1 1", "batch end 1 1", "epoch end 1",
+		"train end 2",
+	}
+	if !reflect.DeepEqual(callback.events, expected) {
+		t.Fatalf("Unexpected events: %v", callback.events)
+	}
+}
+
+func TestEarlyStoppingAndBestModelCheckpoint(t *testing.T) {
+	data := callbackTestData()
+	// inverted labels, so validation loss grows while the model learns training data
+	validation := model.ModelData{X: data.X, Y: *mat.NewDense(8, 1, []float64{1, 0, 1, 0, 1, 0, 1, 0})}
+	batchSize := 4
+	path := filepath.Join(t.TempDir(), "best.json")
+
+	earlyStopping := model.EarlyStopping{ValidationData: validation, BatchSize: &batchSize, Patience: 2}
+	checkpoint := model.BestModelCheckpoint{ValidationData: validation, BatchSize: &batchSize, Path: path}
+
+	m := newCheckpointTestModel()
+	history := m.Train(data, 50, &batchSize, 100, nil, model.WithCallbacks(&earlyStopping, &checkpoint))
+
+	if earlyStopping.StoppedEpoch < 0 || len(history.Epochs) != earlyStopping.StoppedEpoch+1 {
+		t.Fatalf("Training is not stopped early: %v epochs, stopped at %v", len(history.Epochs), earlyStopping.StoppedEpoch)
+	}
+	if earlyStopping.StoppedEpoch-earlyStopping.BestEpoch != earlyStopping.Patience {
+		t.Fatalf("Unexpected stop: best epoch %v, stopped at %v", earlyStopping.BestEpoch, earlyStopping.StoppedEpoch)
+	}
+	if checkpoint.BestEpoch != earlyStopping.BestEpoch {
+		t.Fatalf("Unexpected best epoch: %v", checkpoint.BestEpoch)
+	}
+
+	provider := model.JSONModelDataProvider{}
+	best, err := provider.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluation := best.Evaluate(validation, &batchSize)
+	if evaluation.Loss != checkpoint.BestLoss {
+		t.Fatalf("Stored model is not the best one: %v, expected %v", evaluation.Loss, checkpoint.BestLoss)
+	}
+}
+
+func TestCSVLogger(t *testing.T) {
+	batchSize := 4
+	path := filepath.Join(t.TempDir(), "log.csv")
+
+	m := newCheckpointTestModel()
+	m.Train(callbackTestData(), 2, &batchSize, 100, nil, model.WithCallbacks(&model.CSVLogger{Path: path}))
+	m.Train(callbackTestData(), 1, &batchSize, 100, nil, model.WithCallbacks(&model.CSVLogger{Path: path, Append: true}))
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// header, 3 epochs and 2 appended epochs
+	if len(rows) != 6 || rows[0][0] != "epoch" || rows[3][0] != "2" || rows[5][0] != "1" {
+		t.Fatalf("Unexpected rows: %v", rows)
+	}
+}
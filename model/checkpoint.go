@@ -0,0 +1,164 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"main/layer"
+	"main/model/marshaling"
+	"os"
+	"path/filepath"
+)
+
+// TrainingState is position of the training stored in checkpoints
+type TrainingState struct {
+	// number of finished epochs
+	Epoch int `json:"epoch"`
+	// number of finished training steps of all epochs
+	Step int `json:"step"`
+}
+
+type checkpointData struct {
+	// the same document as stored by ModelDataProvider; includes optimizer with its iterations and learning rate
+	Model json.RawMessage `json:"model"`
+	// marshaling.OptimizerStateWrapper of every layer by index in Model.Layers; empty for layers without parameters
+	OptimizerState []json.RawMessage `json:"optimizer_state"`
+	State          TrainingState     `json:"state"`
+	Seed           uint64            `json:"seed"`
+	// state of random source used by layers
+	Random []byte `json:"random"`
+}
+
+// SaveCheckpoint stores everything required to resume training: model, optimizer state of every layer,
+// training position and random state. JSON is used for .json files, binary format otherwise
+func (m *Model) SaveCheckpoint(path string, state TrainingState) error {
+	encode := func(tensors *marshaling.TensorTable) ([]byte, error) {
+		model, err := encodeModel(m, tensors)
+		if err != nil {
+			return nil, err
+		}
+		random, err := m.source.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+
+		data := checkpointData{
+			Model:          model,
+			OptimizerState: make([]json.RawMessage, len(m.Layers)),
+			State:          state,
+			Seed:           m.Seed,
+			Random:         random,
+		}
+		for i, item := range m.Layers {
+			var state marshaling.OptimizerStateWrapper
+			if trainableLayer, ok := item.(layer.TrainableLayer); ok {
+				state = marshaling.WrapOptimizerState(trainableLayer.Parameters(), tensors)
+			}
+			data.OptimizerState[i], err = json.Marshal(state)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return json.Marshal(data)
+	}
+
+	if filepath.Ext(path) != ".json" {
+		return writeBinaryDocument(path, false, encode)
+	}
+
+	d, err := encode(nil)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, func(w io.Writer) error {
+		_, err := w.Write(d)
+		return err
+	})
+}
+
+// writes a temporary file next to path and renames it over path
+// interrupted or failed writes leave the previous file, e.g. the last checkpoint, intact
+func writeFileAtomically(path string, write func(w io.Writer) error) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// fails harmlessly after the rename
+	defer os.Remove(file.Name())
+
+	err = write(file)
+	if err == nil {
+		err = file.Chmod(0644)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// LoadCheckpoint replaces layers, loss, optimizer, accuracy and scaler of the model with ones stored by SaveCheckpoint
+// returns position of the training to continue from
+func (m *Model) LoadCheckpoint(path string) (TrainingState, error) {
+	var loaded *Model
+	data := checkpointData{}
+
+	decode := func(document []byte, tensors *marshaling.TensorTable) error {
+		err := json.Unmarshal(document, &data)
+		if err != nil {
+			return err
+		}
+		loaded, err = decodeModel(data.Model, tensors)
+		if err != nil {
+			return err
+		}
+		if len(data.OptimizerState) != len(loaded.Layers) {
+			return errors.New("optimizer state doesn't match layers")
+		}
+		for i, item := range loaded.Layers {
+			if trainableLayer, ok := item.(layer.TrainableLayer); ok {
+				state, err := marshaling.UnmarshalOptimizerState(data.OptimizerState[i], tensors)
+				if err != nil {
+					return err
+				}
+				err = state.Restore(trainableLayer.Parameters())
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if filepath.Ext(path) != ".json" {
+		err = readBinaryDocument(path, decode)
+	} else {
+		var d []byte
+		d, err = os.ReadFile(path)
+		if err == nil {
+			err = decode(d, nil)
+		}
+	}
+	if err != nil {
+		return TrainingState{}, err
+	}
+
+	m.Name = loaded.Name
+	m.Layers = loaded.Layers
+	m.Set(loaded.Loss, loaded.Optimizer, loaded.Accuracy)
+	m.Scaler = loaded.Scaler
+	m.Seed = data.Seed
+	m.Finalize()
+
+	err = m.source.UnmarshalBinary(data.Random)
+	if err != nil {
+		return TrainingState{}, err
+	}
+	return data.State, nil
+}
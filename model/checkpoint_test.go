@@ -0,0 +1,111 @@
+package model_test
+
+import (
+	"errors"
+	"io"
+	"main/accuracy"
+	"main/activation"
+	"main/layer"
+	"main/loss"
+	"main/model"
+	"main/optimizer"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestCheckpointResumeMatchesUninterruptedTraining(t *testing.T) {
+	for _, name := range []string{"checkpoint.json", "checkpoint.bin"} {
+		dir := t.TempDir()
+		data := model.ModelData{
+			X: *mat.NewDense(8, 2, []float64{0.1, 0.2, 0.9, 0.8, 0.2, 0.1, 0.7, 0.9, 0.3, 0.2, 0.8, 0.7, 0.1, 0.3, 0.9, 0.9}),
+			Y: *mat.NewDense(8, 1, []float64{0, 1, 0, 1, 0, 1, 0, 1}),
+		}
+		batchSize := 3
+
+		interrupted := newCheckpointTestModel()
+		modelPath := filepath.Join(dir, "initial.json")
+		provider := model.JSONModelDataProvider{}
+		err := provider.Store(modelPath, interrupted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uninterrupted, err := provider.Load(modelPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// epochs 0, 1 then checkpoint and resume for epochs 2, 3
+		checkpointPath := filepath.Join(dir, name)
+		interrupted.Train(data, 1, &batchSize, 100, nil, model.CheckpointEvery(2, checkpointPath))
+		interrupted.Train(data, 3, &batchSize, 100, nil, model.ResumeFrom(checkpointPath))
+
+		uninterrupted.Train(data, 3, &batchSize, 100, nil)
+
+		for i, l := range interrupted.Layers {
+			trainable, ok := l.(layer.TrainableLayer)
+			if !ok {
+				continue
+			}
+			expected := uninterrupted.Layers[i].(layer.TrainableLayer).Parameters()
+			for j, parameter := range trainable.Parameters() {
+				if !mat.Equal(parameter.Values, expected[j].Values) {
+					t.Fatalf("%v: parameter %v of layer %v differs after resume", name, parameter.Name, i)
+				}
+			}
+		}
+
+		adam := interrupted.Optimizer.(*optimizer.OptimizerAdam)
+		if adam.Iterations != uninterrupted.Optimizer.(*optimizer.OptimizerAdam).Iterations {
+			t.Fatalf("%v: unexpected optimizer iterations: %v", name, adam.Iterations)
+		}
+	}
+}
+
+func newCheckpointTestModel() *model.Model {
+	m := model.Model{}
+	m.Add((&layer.DenseLayer{}).Initialization(2, 8))
+	m.Add(&activation.Activation_ReLU{})
+	m.Add((&layer.DropoutLayer{}).Initialization(0.2))
+	m.Add((&layer.DenseLayer{}).Initialization(8, 1))
+	m.Add(&activation.SigmoidActivation{})
+	o := optimizer.NewAdam()
+	o.LearningRate, o.CurrentLearningRate, o.Decay = 0.05, 0.05, 1e-3
+	m.Set(&loss.BinaryCrossentropyLoss{}, &o, &accuracy.BinaryCategorialAccuracy{})
+	m.Finalize()
+	return &m
+}
+
+func TestFailedCheckpointWriteKeepsPreviousCheckpoint(t *testing.T) {
+	for _, name := range []string{"checkpoint.json", "checkpoint.bin"} {
+		dir := t.TempDir()
+		path := filepath.Join(dir, name)
+		m := newCheckpointTestModel()
+		err := m.SaveCheckpoint(path, model.TrainingState{Epoch: 3, Step: 9})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// process dies after a part of the next checkpoint is written
+		err = model.WriteFileAtomically(path, func(w io.Writer) error {
+			w.Write([]byte(`{"model": {`))
+			return errors.New("interrupted")
+		})
+		if err == nil {
+			t.Fatalf("%v: missing error of interrupted write", name)
+		}
+
+		state, err := newCheckpointTestModel().LoadCheckpoint(path)
+		if err != nil {
+			t.Fatalf("%v: previous checkpoint is not loadable: %v", name, err)
+		}
+		if state.Epoch != 3 || state.Step != 9 {
+			t.Fatalf("%v: unexpected state: %v", name, state)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Fatalf("%v: temporary files are left: %v", name, entries)
+		}
+	}
+}
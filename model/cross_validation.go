@@ -0,0 +1,45 @@
+package model
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// CrossValidation is result of CrossValidate
+type CrossValidation struct {
+	// evaluation of validation data of every fold
+	Folds     []Evaluation
+	Histories []*History
+
+	Mean Evaluation
+	// population standard deviation of fold evaluations
+	Std Evaluation
+}
+
+// CrossValidate trains a new model created by factory for every fold and evaluates it on the validation data of the fold
+// factory has to return finalized model, e.g. with freshly initialized weights
+func CrossValidate(factory func() *Model, data ModelData, kfold KFold, epochs int, batchSize *int, printEvery int, opts ...TrainOption) CrossValidation {
+	result := CrossValidation{}
+	for _, fold := range kfold.Folds(data) {
+		m := factory()
+		history := m.Train(fold.Training, epochs, batchSize, printEvery, &fold.Validation, opts...)
+		result.Folds = append(result.Folds, *history.Validation)
+		result.Histories = append(result.Histories, history)
+	}
+
+	losses := make([]float64, len(result.Folds))
+	accuracies := make([]float64, len(result.Folds))
+	for i, evaluation := range result.Folds {
+		losses[i] = evaluation.Loss
+		accuracies[i] = evaluation.Accuracy
+	}
+	result.Mean.Loss, result.Std.Loss = meanStd(losses)
+	result.Mean.Accuracy, result.Std.Accuracy = meanStd(accuracies)
+	return result
+}
+
+func meanStd(values []float64) (float64, float64) {
+	mean := stat.Mean(values, nil)
+	return mean, math.Sqrt(stat.MomentAbout(2, values, mean, nil))
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"encoding/csv"
+	"log"
+	"os"
+	"strconv"
+)
+
+// CSVLogger writes metrics of every epoch into CSV file
+type CSVLogger struct {
+	BaseCallback
+
+	Path string
+	// appends rows to existing file instead of overwriting it, e.g. when training is resumed
+	Append bool
+
+	file   *os.File
+	writer *csv.Writer
+}
+
+func (c *CSVLogger) OnTrainBegin(m *Model) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if c.Append {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	var err error
+	c.file, err = os.OpenFile(c.Path, flags, 0644)
+	if err != nil {
+		log.Fatalf("Failed to open CSV log: %v", err)
+	}
+	c.writer = csv.NewWriter(c.file)
+
+	info, err := c.file.Stat()
+	if err != nil {
+		log.Fatalf("Failed to open CSV log: %v", err)
+	}
+	if info.Size() == 0 {
+		c.write([]string{"epoch", "loss", "data_loss", "regularization_loss", "accuracy", "learning_rate", "gradient_norm", "validation_loss", "validation_accuracy", "duration"})
+	}
+}
+
+// validation columns are empty for epochs without validation
+func (c *CSVLogger) OnEpochEnd(m *Model, record EpochRecord) {
+	validationLoss, validationAccuracy := "", ""
+	if record.Validation != nil {
+		validationLoss = formatFloat(record.Validation.Loss)
+		validationAccuracy = formatFloat(record.Validation.Accuracy)
+	}
+
+	c.write([]string{
+		strconv.Itoa(record.Epoch),
+		formatFloat(record.Loss),
+		formatFloat(record.DataLoss),
+		formatFloat(record.RegularizationLoss),
+		formatFloat(record.Accuracy),
+		formatFloat(record.LearningRate),
+		formatFloat(record.GradientNorm),
+		validationLoss,
+		validationAccuracy,
+		formatFloat(record.Duration.Seconds()),
+	})
+}
+
+func (c *CSVLogger) OnTrainEnd(m *Model, history *History) {
+	err := c.file.Close()
+	if err != nil {
+		log.Fatalf("Failed to close CSV log: %v", err)
+	}
+}
+
+// rows are flushed immediately, so the log is complete even if training is interrupted
+func (c *CSVLogger) write(row []string) {
+	c.writer.Write(row)
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		log.Fatalf("Failed to write CSV log: %v", err)
+	}
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+// DataLoader provides batches of samples for Model.TrainWithLoader, Model.EvaluateWithLoader and Model.PredictWithLoader
+type DataLoader interface {
+	// starts iteration over batches of the epoch
+	Begin(epoch int)
+	// returns next batch of the epoch; ok is false when the epoch is over
+	// Y of the batch is empty if loader doesn't have targets
+	Next() (batch ModelData, ok bool)
+	// number of batches in one epoch; -1 if it's unknown
+	Steps() int
+	// error which stopped the epoch early, e.g. failed reading of a file
+	Err() error
+}
+
+// BatchSampler splits indexes of samples into batches of an epoch
+type BatchSampler struct {
+	// number of samples in one batch; 0 means one batch with all samples
+	BatchSize int
+
+	// samples are shuffled at the beginning of every epoch
+	// order depends only on Seed and epoch, so resumed training sees the same batches
+	Shuffle bool
+	Seed    uint64
+
+	// drops the last batch if it has less than BatchSize samples
+	DropLast bool
+}
+
+// returns indexes of samples of every batch of the epoch
+func (sampler BatchSampler) Batches(samples int, epoch int) [][]int {
+	order := make([]int, samples)
+	for i := range order {
+		order[i] = i
+	}
+	if sampler.Shuffle {
+		random := rand.New(rand.NewSource(sampler.Seed + uint64(epoch)))
+		random.Shuffle(samples, func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+	}
+
+	size := sampler.size(samples)
+	batches := make([][]int, 0, sampler.Steps(samples))
+	for from := 0; from < samples; from += size {
+		to := min(from+size, samples)
+		if sampler.DropLast && to-from < size {
+			break
+		}
+		batches = append(batches, order[from:to])
+	}
+	return batches
+}
+
+func (sampler BatchSampler) Steps(samples int) int {
+	size := sampler.size(samples)
+	if size == 0 {
+		return 0
+	}
+	if sampler.DropLast {
+		return samples / size
+	}
+	return (samples + size - 1) / size
+}
+
+func (sampler BatchSampler) size(samples int) int {
+	if sampler.BatchSize <= 0 {
+		return samples
+	}
+	return sampler.BatchSize
+}
+
+// MemoryLoader splits ModelData into batches
+type MemoryLoader struct {
+	BatchSampler
+	Data ModelData
+
+	batches  [][]int
+	position int
+}
+
+// creates loader which splits data in the original order; nil batchSize means one batch with all samples
+func NewMemoryLoader(data ModelData, batchSize *int) *MemoryLoader {
+	loader := &MemoryLoader{Data: data}
+	if batchSize != nil {
+		loader.BatchSize = *batchSize
+	}
+	return loader
+}
+
+func (loader *MemoryLoader) Begin(epoch int) {
+	loader.position = 0
+	loader.batches = loader.Batches(loader.samples(), epoch)
+}
+
+func (loader *MemoryLoader) Next() (ModelData, bool) {
+	if loader.position >= len(loader.batches) {
+		return ModelData{}, false
+	}
+	indexes := loader.batches[loader.position]
+	loader.position += 1
+
+	if !loader.Shuffle {
+		// samples of the batch are contiguous
+		from, to := indexes[0], indexes[len(indexes)-1]+1
+		return ModelData{X: sliceRows(&loader.Data.X, from, to), Y: sliceRows(&loader.Data.Y, from, to)}, true
+	}
+	return ModelData{X: gatherRows(&loader.Data.X, indexes), Y: gatherRows(&loader.Data.Y, indexes)}, true
+}
+
+func (loader *MemoryLoader) Steps() int {
+	return loader.BatchSampler.Steps(loader.samples())
+}
+
+func (loader *MemoryLoader) Err() error {
+	return nil
+}
+
+func (loader *MemoryLoader) samples() int {
+	if loader.Data.X.IsEmpty() {
+		return 0
+	}
+	rows, _ := loader.Data.X.Dims()
+	return rows
+}
+
+func sliceRows(values *mat.Dense, from, to int) mat.Dense {
+	if values.IsEmpty() {
+		return mat.Dense{}
+	}
+	_, cols := values.Dims()
+	return *mat.DenseCopyOf(values.Slice(from, to, 0, cols))
+}
+
+func gatherRows(values *mat.Dense, indexes []int) mat.Dense {
+	if values.IsEmpty() {
+		return mat.Dense{}
+	}
+	_, cols := values.Dims()
+	result := mat.NewDense(len(indexes), cols, nil)
+	for i, index := range indexes {
+		result.SetRow(i, values.RawRowView(index))
+	}
+	return *result
+}
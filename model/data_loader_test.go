@@ -0,0 +1,117 @@
+package model_test
+
+import (
+	"main/model"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func loaderTestData(samples int) model.ModelData {
+	x := mat.NewDense(samples, 2, nil)
+	y := mat.NewDense(samples, 1, nil)
+	for i := 0; i < samples; i++ {
+		x.SetRow(i, []float64{float64(i), float64(-i)})
+		y.Set(i, 0, float64(i))
+	}
+	return model.ModelData{X: *x, Y: *y}
+}
+
+// returns sample indexes of every batch of the epoch
+func epochBatches(t *testing.T, loader model.DataLoader, epoch int) [][]int {
+	batches := [][]int{}
+	loader.Begin(epoch)
+	for {
+		batch, ok := loader.Next()
+		if !ok {
+			break
+		}
+		rows, _ := batch.X.Dims()
+		indexes := make([]int, rows)
+		for i := range indexes {
+			indexes[i] = int(batch.X.At(i, 0))
+			if batch.Y.At(i, 0) != batch.X.At(i, 0) || batch.X.At(i, 1) != -batch.X.At(i, 0) {
+				t.Fatalf("Samples and targets are mixed up: %v", batch)
+			}
+		}
+		batches = append(batches, indexes)
+	}
+	if steps := loader.Steps(); steps != len(batches) {
+		t.Fatalf("Unexpected steps: %v, batches: %v", steps, len(batches))
+	}
+	return batches
+}
+
+func TestMemoryLoaderKeepsLastBatch(t *testing.T) {
+	batchSize := 3
+	loader := model.NewMemoryLoader(loaderTestData(7), &batchSize)
+
+	batches := epochBatches(t, loader, 0)
+	expected := [][]int{{0, 1, 2}, {3, 4, 5}, {6}}
+	if !reflect.DeepEqual(batches, expected) {
+		t.Fatalf("Unexpected batches: %v", batches)
+	}
+
+	loader.DropLast = true
+	batches = epochBatches(t, loader, 0)
+	if !reflect.DeepEqual(batches, expected[:2]) {
+		t.Fatalf("Unexpected batches with drop last: %v", batches)
+	}
+}
+
+func TestMemoryLoaderShuffle(t *testing.T) {
+	batchSize := 4
+	loader := model.NewMemoryLoader(loaderTestData(10), &batchSize)
+	loader.Shuffle = true
+	loader.Seed = 7
+
+	first := epochBatches(t, loader, 0)
+	second := epochBatches(t, loader, 1)
+	if reflect.DeepEqual(first, second) {
+		t.Fatal("Order has to change between epochs")
+	}
+	if !reflect.DeepEqual(first, epochBatches(t, loader, 0)) {
+		t.Fatal("Order has to depend only on seed and epoch")
+	}
+
+	all := []int{}
+	for _, batch := range second {
+		all = append(all, batch...)
+	}
+	sort.Ints(all)
+	if !reflect.DeepEqual(all, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Fatalf("Every sample has to be used once: %v", all)
+	}
+}
+
+func TestPredictReturnsEverySample(t *testing.T) {
+	batchSize := 3
+	m := newCheckpointTestModel()
+	data := loaderTestData(7)
+
+	predictions := m.Predict(&data.X, &batchSize)
+	rows, _ := predictions.Dims()
+	if rows != 7 {
+		t.Fatalf("Unexpected number of predictions: %v", rows)
+	}
+	if !mat.Equal(&predictions, m.Forward(data.X, false)) {
+		t.Fatal("Batched predictions differ from one batch")
+	}
+}
+
+type emptyLoader struct{}
+
+func (emptyLoader) Begin(epoch int)               {}
+func (emptyLoader) Next() (model.ModelData, bool) { return model.ModelData{}, false }
+func (emptyLoader) Steps() int                    { return 0 }
+func (emptyLoader) Err() error                    { return nil }
+
+func TestEvaluateEmptyLoader(t *testing.T) {
+	m := newCheckpointTestModel()
+	evaluation := m.EvaluateWithLoader(emptyLoader{})
+	if evaluation != (model.Evaluation{}) {
+		t.Fatalf("Unexpected evaluation of empty loader: %+v", evaluation)
+	}
+}
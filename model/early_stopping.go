@@ -0,0 +1,51 @@
+package model
+
+import "math"
+
+// EarlyStopping stops training when loss on validation data doesn't improve for Patience epochs
+type EarlyStopping struct {
+	BaseCallback
+
+	// data evaluated after every epoch; optional if Train validates every epoch itself
+	// epochs without validation are skipped
+	ValidationData ModelData
+	BatchSize      *int
+
+	// number of epochs without improvement before training is stopped
+	Patience int
+	// minimal decrease of loss counted as improvement
+	MinDelta float64
+
+	BestLoss  float64
+	BestEpoch int
+	// epoch after which training was stopped; -1 if it wasn't stopped
+	StoppedEpoch int
+
+	wait int
+}
+
+func (c *EarlyStopping) OnTrainBegin(m *Model) {
+	c.BestLoss = math.Inf(1)
+	c.BestEpoch = -1
+	c.StoppedEpoch = -1
+	c.wait = 0
+}
+
+func (c *EarlyStopping) OnEpochEnd(m *Model, record EpochRecord) {
+	loss, ok := validationLoss(m, record, c.ValidationData, c.BatchSize)
+	if !ok {
+		return
+	}
+	if loss < c.BestLoss-c.MinDelta {
+		c.BestLoss = loss
+		c.BestEpoch = record.Epoch
+		c.wait = 0
+		return
+	}
+
+	c.wait += 1
+	if c.wait >= c.Patience {
+		c.StoppedEpoch = record.Epoch
+		m.StopTraining()
+	}
+}
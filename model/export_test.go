@@ -0,0 +1,4 @@
+package model
+
+// exported for tests of interrupted writes
+var WriteFileAtomically = writeFileAtomically
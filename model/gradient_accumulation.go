@@ -0,0 +1,86 @@
+package model
+
+import (
+	"main/layer"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// AccumulateGradients makes one optimizer step per k micro-batches of the loader
+// gradients of micro-batches are weighted by their number of samples, so the step is the same as one step
+// with all samples in one batch, while memory is used only for one micro-batch
+// layers using statistics of the batch, e.g. batch normalization, still see only micro-batches
+func AccumulateGradients(k int) TrainOption {
+	return func(options *trainOptions) {
+		options.accumulate = k
+	}
+}
+
+// reads up to count batches; fewer are returned at the end of the epoch
+func nextBatches(loader DataLoader, count int) []ModelData {
+	batches := make([]ModelData, 0, count)
+	for len(batches) < count {
+		batch, ok := loader.Next()
+		if !ok {
+			break
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// gradientAccumulator sums gradients of micro-batches weighted by their number of samples
+type gradientAccumulator struct {
+	// sums of gradients of every parameter of trainable layers in order of the model
+	sums    []*mat.Dense
+	samples int
+}
+
+// adds gradients computed by the last backward pass of a micro-batch with given number of samples
+func (accumulator *gradientAccumulator) add(layers []layer.LayerInterface, samples int) {
+	parameters := trainableParameters(layers)
+	if accumulator.sums == nil {
+		accumulator.sums = make([]*mat.Dense, len(parameters))
+		for i, parameter := range parameters {
+			rows, cols := parameter.Gradients.Dims()
+			accumulator.sums[i] = mat.NewDense(rows, cols, nil)
+		}
+	}
+
+	for i, parameter := range parameters {
+		weighted := mat.DenseCopyOf(parameter.Gradients)
+		weighted.Scale(float64(samples), weighted)
+		accumulator.sums[i].Add(accumulator.sums[i], weighted)
+	}
+	accumulator.samples += samples
+}
+
+// replaces gradients of parameters by the weighted mean of accumulated ones
+func (accumulator *gradientAccumulator) apply(layers []layer.LayerInterface) {
+	for i, parameter := range trainableParameters(layers) {
+		parameter.Gradients.Scale(1/float64(accumulator.samples), accumulator.sums[i])
+	}
+}
+
+func trainableParameters(layers []layer.LayerInterface) []layer.Parameter {
+	parameters := []layer.Parameter{}
+	for _, item := range layers {
+		if trainableLayer, ok := item.(layer.TrainableLayer); ok {
+			parameters = append(parameters, trainableLayer.Parameters()...)
+		}
+	}
+	return parameters
+}
+
+// mean of values weighted by weights; a single value is returned as it is
+func weightedMean(values, weights []float64) float64 {
+	if len(values) == 1 {
+		return values[0]
+	}
+	sum, total := 0.0, 0.0
+	for i, value := range values {
+		sum += value * weights[i]
+		total += weights[i]
+	}
+	return sum / total
+}
@@ -0,0 +1,53 @@
+package model_test
+
+import (
+	"main/accuracy"
+	"main/activation"
+	"main/layer"
+	"main/loss"
+	"main/model"
+	"main/optimizer"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestAccumulatedGradientsMatchLargeBatch(t *testing.T) {
+	m := model.Model{}
+	first := (&layer.DenseLayer{}).Initialization(2, 4)
+	first.L2 = layer.Regularizer{Weight: 1e-3, Bias: 1e-3}
+	m.Add(first)
+	m.Add(&activation.Activation_ReLU{})
+	m.Add((&layer.DenseLayer{}).Initialization(4, 1))
+	m.Add(&activation.SigmoidActivation{})
+	o := optimizer.NewSGD(0.5, 0, 0.9)
+	m.Set(&loss.BinaryCrossentropyLoss{}, &o, &accuracy.BinaryCategorialAccuracy{})
+	m.Finalize()
+	accumulated := storeAndLoad(t, &m)
+
+	// 8 samples: steps of 6 and 2 samples, i.e. micro-batches of 3 and 3, and a single micro-batch of 2
+	large, micro := 6, 3
+	expected := m.Train(callbackTestData(), 0, &large, 0, nil, model.RecordSteps())
+	history := accumulated.Train(callbackTestData(), 0, &micro, 0, nil, model.RecordSteps(), model.AccumulateGradients(2))
+
+	if len(history.Steps) != 2 {
+		t.Fatalf("Expected 2 optimizer steps, got %v", len(history.Steps))
+	}
+	for i, step := range history.Steps {
+		if diff := step.Loss - expected.Steps[i].Loss; diff > 1e-12 || diff < -1e-12 {
+			t.Errorf("step %v: loss %v differs from %v", i, step.Loss, expected.Steps[i].Loss)
+		}
+	}
+	for i := range m.Layers {
+		l, ok := m.Layers[i].(layer.TrainableLayer)
+		if !ok {
+			continue
+		}
+		for j, parameter := range l.Parameters() {
+			actual := accumulated.Layers[i].(layer.TrainableLayer).Parameters()[j]
+			if !mat.EqualApprox(parameter.Values, actual.Values, 1e-12) {
+				t.Fatalf("layer %v: %v differ from training with large batches", i, parameter.Name)
+			}
+		}
+	}
+}
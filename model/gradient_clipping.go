@@ -0,0 +1,56 @@
+package model
+
+import (
+	"main/layer"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ClipByValue limits every gradient value to -limit...limit before the optimizer step
+func ClipByValue(limit float64) TrainOption {
+	return func(options *trainOptions) {
+		options.clipValue = limit
+	}
+}
+
+// ClipByGlobalNorm scales gradients of all trainable layers down, so their global L2 norm is at most maxNorm
+// direction of the update is kept; it's applied after ClipByValue if both are used
+func ClipByGlobalNorm(maxNorm float64) TrainOption {
+	return func(options *trainOptions) {
+		options.clipNorm = maxNorm
+	}
+}
+
+// clips gradients of all trainable layers in place and returns their global norm before clipping
+func clipGradients(layers []layer.LayerInterface, clipValue, clipNorm float64) float64 {
+	parameters := trainableParameters(layers)
+	norm := globalNorm(parameters)
+
+	if clipValue > 0 {
+		for _, parameter := range parameters {
+			parameter.Gradients.Apply(func(i, j int, v float64) float64 {
+				return max(-clipValue, min(v, clipValue))
+			}, parameter.Gradients)
+		}
+	}
+
+	if clipNorm > 0 {
+		// norm after clipping by value
+		if clippedNorm := globalNorm(parameters); clippedNorm > clipNorm {
+			for _, parameter := range parameters {
+				parameter.Gradients.Scale(clipNorm/clippedNorm, parameter.Gradients)
+			}
+		}
+	}
+	return norm
+}
+
+// L2 norm of gradients of all parameters as one vector
+func globalNorm(parameters []layer.Parameter) float64 {
+	sum := 0.0
+	for _, parameter := range parameters {
+		sum += math.Pow(mat.Norm(parameter.Gradients, 2), 2)
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,71 @@
+package model_test
+
+import (
+	"main/accuracy"
+	"main/activation"
+	"main/layer"
+	"main/loss"
+	"main/model"
+	"main/optimizer"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// trains one step of SGD with learning rate 1, so parameters change by clipped gradients
+// gradients of all parameters are large as predictions of initialized layer are close to 0
+// returns change of every parameter value and the recorded step
+func clippedStep(t *testing.T, opts ...model.TrainOption) ([]float64, model.StepRecord) {
+	m := model.Model{}
+	dense := (&layer.DenseLayer{}).Initialization(2, 1)
+	m.Add(dense)
+	m.Add(&activation.LinearActivation{})
+	o := optimizer.NewSGD(1, 0, 0)
+	m.Set(&loss.MeanSquaredErrorLoss{}, &o, &accuracy.RegressionAccuracy{})
+	m.Finalize()
+
+	before := []float64{}
+	for _, parameter := range dense.Parameters() {
+		before = append(before, mat.DenseCopyOf(parameter.Values).RawMatrix().Data...)
+	}
+
+	data := model.ModelData{
+		X: *mat.NewDense(2, 2, []float64{10, 20, -30, 40}),
+		Y: *mat.NewDense(2, 1, []float64{100, 100}),
+	}
+	history := m.Train(data, 0, nil, 0, nil, append(opts, model.RecordSteps())...)
+
+	changes := []float64{}
+	for _, parameter := range dense.Parameters() {
+		for _, v := range parameter.Values.RawMatrix().Data {
+			changes = append(changes, v-before[len(changes)])
+		}
+	}
+	return changes, history.Steps[0]
+}
+
+func TestClipByGlobalNorm(t *testing.T) {
+	changes, record := clippedStep(t, model.ClipByGlobalNorm(0.5))
+
+	norm := 0.0
+	for _, change := range changes {
+		norm += change * change
+	}
+	if math.Abs(math.Sqrt(norm)-0.5) > 1e-9 {
+		t.Fatalf("Update norm has to be clipped to 0.5, got %v", math.Sqrt(norm))
+	}
+	if record.GradientNorm <= 0.5 {
+		t.Fatalf("History has to contain norm before clipping, got %v", record.GradientNorm)
+	}
+}
+
+func TestClipByValue(t *testing.T) {
+	changes, _ := clippedStep(t, model.ClipByValue(0.1))
+
+	for _, change := range changes {
+		if math.Abs(math.Abs(change)-0.1) > 1e-9 {
+			t.Fatalf("Every gradient has to be clipped to 0.1: %v", changes)
+		}
+	}
+}
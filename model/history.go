@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// History collects metrics of Model.Train
+type History struct {
+	Epochs []EpochRecord
+	// every training step; recorded only with RecordSteps option
+	Steps []StepRecord
+	// evaluation of validation data after training; nil without validation data
+	Validation *Evaluation
+	// wall time of the whole training
+	Duration time.Duration
+}
+
+// StepRecord describes one training step (batch)
+type StepRecord struct {
+	Epoch              int
+	Step               int
+	Loss               float64
+	DataLoss           float64
+	RegularizationLoss float64
+	Accuracy           float64
+	LearningRate       float64
+	// global L2 norm of gradients of all trainable layers before clipping
+	GradientNorm float64
+}
+
+// EpochRecord describes one epoch with metrics accumulated over all its steps
+type EpochRecord struct {
+	Epoch              int
+	Loss               float64
+	DataLoss           float64
+	RegularizationLoss float64
+	Accuracy           float64
+	LearningRate       float64
+	// mean of StepRecord.GradientNorm of all steps of the epoch
+	GradientNorm float64
+	// evaluation of validation data; nil if it wasn't evaluated after this epoch
+	Validation *Evaluation
+	Duration   time.Duration
+}
+
+// Evaluation is result of Model.Evaluate
+type Evaluation struct {
+	Loss     float64
+	Accuracy float64
+}
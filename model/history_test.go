@@ -0,0 +1,113 @@
+package model_test
+
+import (
+	"main/model"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type countingReporter struct {
+	trainBegins, epochBegins, steps, epochs, evaluations int
+}
+
+func (r *countingReporter) TrainBegin(m *model.Model)                          { r.trainBegins++ }
+func (r *countingReporter) EpochBegin(m *model.Model, epoch int)               { r.epochBegins++ }
+func (r *countingReporter) StepEnd(m *model.Model, record model.StepRecord)    { r.steps++ }
+func (r *countingReporter) EpochEnd(m *model.Model, record model.EpochRecord)  { r.epochs++ }
+func (r *countingReporter) Evaluation(m *model.Model, result model.Evaluation) { r.evaluations++ }
+
+func TestTrainHistory(t *testing.T) {
+	data := model.ModelData{
+		X: *mat.NewDense(8, 2, []float64{0.1, 0.2, 0.9, 0.8, 0.2, 0.1, 0.7, 0.9, 0.3, 0.2, 0.8, 0.7, 0.1, 0.3, 0.9, 0.9}),
+		Y: *mat.NewDense(8, 1, []float64{0, 1, 0, 1, 0, 1, 0, 1}),
+	}
+	batchSize := 3
+	reporter := countingReporter{}
+
+	m := newCheckpointTestModel()
+	m.Reporter = &reporter
+	history := m.Train(data, 4, &batchSize, 2, &data, model.RecordSteps())
+
+	// epochs are counted from 0 to epochs inclusive
+	if len(history.Epochs) != 5 {
+		t.Fatalf("Unexpected number of epochs: %v", len(history.Epochs))
+	}
+	if len(history.Steps) != 5*3 {
+		t.Fatalf("Unexpected number of steps: %v", len(history.Steps))
+	}
+	for i, record := range history.Epochs {
+		if record.Epoch != i || record.Duration <= 0 || math.Abs(record.Loss-record.DataLoss-record.RegularizationLoss) > 1e-12 {
+			t.Fatalf("Unexpected epoch record: %+v", record)
+		}
+	}
+	if history.Validation == nil {
+		t.Fatal("Missing validation")
+	}
+
+	evaluation := m.Evaluate(data, &batchSize)
+	if *history.Validation != evaluation {
+		t.Fatalf("Unexpected evaluation: %+v, history: %+v", evaluation, *history.Validation)
+	}
+
+	// steps 0 and 2 of every epoch
+	expected := countingReporter{trainBegins: 1, epochBegins: 5, steps: 5 * 2, epochs: 5, evaluations: 2}
+	if reporter != expected {
+		t.Fatalf("Unexpected reporter calls: %+v", reporter)
+	}
+}
+
+func TestValidateEvery(t *testing.T) {
+	data := callbackTestData()
+	batchSize := 3
+
+	m := newCheckpointTestModel()
+	path := filepath.Join(t.TempDir(), "model.json")
+	provider := model.JSONModelDataProvider{}
+	err := provider.Store(path, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unvalidated, err := provider.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history := m.Train(data, 4, &batchSize, 100, &data, model.ValidateEvery(2))
+	expected := unvalidated.Train(data, 4, &batchSize, 100, nil)
+
+	for i, record := range history.Epochs {
+		if (record.Validation != nil) != (i%2 == 1) {
+			t.Fatalf("Unexpected validation of epoch %v: %v", i, record.Validation)
+		}
+		// validation doesn't affect training metrics
+		if record.Loss != expected.Epochs[i].Loss || record.Accuracy != expected.Epochs[i].Accuracy {
+			t.Fatalf("Epoch %v differs: %+v, expected %+v", i, record, expected.Epochs[i])
+		}
+	}
+
+	accumulated := m.Loss.CalculateAccumulatedLoss()
+	m.Evaluate(data, &batchSize)
+	if m.Loss.CalculateAccumulatedLoss() != accumulated {
+		t.Fatal("Evaluate changes accumulated training loss")
+	}
+}
+
+func TestEarlyStoppingUsesEpochValidation(t *testing.T) {
+	data := callbackTestData()
+	validation := model.ModelData{X: data.X, Y: *mat.NewDense(8, 1, []float64{1, 0, 1, 0, 1, 0, 1, 0})}
+	batchSize := 4
+
+	earlyStopping := model.EarlyStopping{Patience: 2}
+	m := newCheckpointTestModel()
+	history := m.Train(data, 50, &batchSize, 100, &validation, model.ValidateEvery(1), model.WithCallbacks(&earlyStopping))
+
+	if earlyStopping.StoppedEpoch < 0 || len(history.Epochs) != earlyStopping.StoppedEpoch+1 {
+		t.Fatalf("Training is not stopped early: %v epochs", len(history.Epochs))
+	}
+	if history.Epochs[earlyStopping.BestEpoch].Validation.Loss != earlyStopping.BestLoss {
+		t.Fatal("Early stopping doesn't use validation of epochs")
+	}
+}
@@ -0,0 +1,82 @@
+package marshaling
+
+import (
+	"encoding/json"
+	"main/layer"
+	"reflect"
+)
+
+type BatchNormWrapper struct {
+	layer.BatchNormLayer
+	// table to store parameters and statistics in; they are stored as JSON without it
+	Tensors *TensorTable
+}
+
+type batchNormWrapperData struct {
+	Channels        int             `json:"channels"`
+	ChannelSize     int             `json:"channel_size"`
+	Momentum        float64         `json:"momentum"`
+	Epsilon         float64         `json:"epsilon"`
+	Parameters      json.RawMessage `json:"parameters"`
+	RunningMean     DenseWrapper    `json:"running_mean"`
+	RunningVariance DenseWrapper    `json:"running_variance"`
+}
+
+func (value BatchNormWrapper) MarshalJSON() ([]byte, error) {
+	parameters, err := json.Marshal(WrapParameters(value.Parameters(), value.Tensors))
+	if err != nil {
+		return nil, err
+	}
+
+	layerData := batchNormWrapperData{
+		Channels:        value.Channels,
+		ChannelSize:     value.ChannelSize,
+		Momentum:        value.Momentum,
+		Epsilon:         value.Epsilon,
+		Parameters:      parameters,
+		RunningMean:     value.Tensors.Wrap(value.RunningMean),
+		RunningVariance: value.Tensors.Wrap(value.RunningVariance),
+	}
+
+	typeStr := reflect.TypeOf(value.BatchNormLayer).String()
+	data := struct {
+		Type string               `json:"type"`
+		Data batchNormWrapperData `json:"data"`
+	}{
+		Type: typeStr,
+		Data: layerData,
+	}
+
+	return json.Marshal(data)
+}
+
+func (value *BatchNormWrapper) UnmarshalJSON(data []byte) error {
+	// wrappers created in advance resolve tensor references with the table
+	wrap := struct {
+		Type string               `json:"type"`
+		Data batchNormWrapperData `json:"data"`
+	}{Data: batchNormWrapperData{RunningMean: DenseWrapper{Tensors: value.Tensors}, RunningVariance: DenseWrapper{Tensors: value.Tensors}}}
+
+	err := json.Unmarshal(data, &wrap)
+	if err != nil {
+		return err
+	}
+
+	l := layer.NewBatchNormLayer(wrap.Data.Channels, wrap.Data.ChannelSize)
+	l.Momentum = wrap.Data.Momentum
+	l.Epsilon = wrap.Data.Epsilon
+	l.RunningMean = wrap.Data.RunningMean.Dense
+	l.RunningVariance = wrap.Data.RunningVariance.Dense
+
+	parameters, err := UnmarshalParameters(wrap.Data.Parameters, value.Tensors)
+	if err != nil {
+		return err
+	}
+	err = parameters.Restore(l.Parameters())
+	if err != nil {
+		return err
+	}
+
+	value.BatchNormLayer = *l
+	return nil
+}
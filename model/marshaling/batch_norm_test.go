@@ -0,0 +1,38 @@
+package marshaling
+
+import (
+	"errors"
+	"main/layer"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestBatchNormMarshaling(t *testing.T) {
+	l := layer.BatchNormLayer{}
+	l.InitializationWithShape(layer.InputShape{Depths: 3, Height: 2, Width: 2})
+	l.Momentum = 0.9
+	l.Gamma = *mat.NewDense(1, 3, []float64{0.5, 1.5, 2.0})
+	l.RunningMean = *mat.NewDense(1, 3, []float64{0.1, -0.2, 0.3})
+
+	d, err := BatchNormWrapper{BatchNormLayer: l}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loadedWrap := BatchNormWrapper{}
+	err = loadedWrap.UnmarshalJSON(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loadedWrap.Channels != 3 || loadedWrap.ChannelSize != 4 || loadedWrap.Momentum != 0.9 {
+		t.Error(errors.New("mismatch in configuration"))
+	}
+	if !IsEqual(l.Gamma.RawMatrix().Data, loadedWrap.Gamma.RawMatrix().Data) {
+		t.Error(errors.New("mismatch in Gamma"))
+	}
+	if !IsEqual(l.RunningMean.RawMatrix().Data, loadedWrap.RunningMean.RawMatrix().Data) {
+		t.Error(errors.New("mismatch in RunningMean"))
+	}
+}
@@ -10,6 +10,8 @@ import (
 
 type ConvolutionWrapper struct {
 	layer.ConvolutionLayer
+	// table to store kernels and biases in; they are stored as JSON without it
+	Tensors *TensorTable
 }
 
 type convolutionWrapperData struct {
@@ -18,6 +20,8 @@ type convolutionWrapperData struct {
 	OutputShape layer.InputShape  `json:"output_shape"`
 	KernelShape layer.KernelShape `json:"kernel_shape"`
 	KernelSize  int               `json:"kenel_size"`
+	Stride      int               `json:"stride"`
+	Padding     int               `json:"padding"`
 	Kernels     [][]DenseWrapper  `json:"kernels"`
 	Biases      []DenseWrapper    `json:"biases"`
 
@@ -29,12 +33,12 @@ func (value ConvolutionWrapper) MarshalJSON() ([]byte, error) {
 	for i := 0; i < len(value.Kernels); i++ {
 		kernels[i] = make([]DenseWrapper, len(value.Kernels[i]))
 		for j := 0; j < len(kernels[i]); j++ {
-			kernels[i][j] = DenseWrapper{Dense: value.Kernels[i][j]}
+			kernels[i][j] = value.Tensors.Wrap(value.Kernels[i][j])
 		}
 	}
 	biases := make([]DenseWrapper, len(value.Biases))
 	for i := 0; i < len(biases); i++ {
-		biases[i] = DenseWrapper{Dense: value.Biases[i]}
+		biases[i] = value.Tensors.Wrap(value.Biases[i])
 	}
 
 	layerData := convolutionWrapperData{
@@ -42,6 +46,8 @@ func (value ConvolutionWrapper) MarshalJSON() ([]byte, error) {
 		InputShape:  value.InputShape,
 		OutputShape: value.OutputShape,
 		KernelSize:  value.KernelSize,
+		Stride:      value.Stride,
+		Padding:     value.Padding,
 		KernelShape: value.KernelShape,
 		Kernels:     kernels,
 		Biases:      biases,
@@ -60,9 +66,14 @@ func (value ConvolutionWrapper) MarshalJSON() ([]byte, error) {
 }
 
 func (value *ConvolutionWrapper) UnmarshalJSON(data []byte) error {
+	// kernels and biases are decoded separately to resolve tensor references with the table
 	wrap := struct {
-		Type string                 `json:"type"`
-		Data convolutionWrapperData `json:"data"`
+		Type string `json:"type"`
+		Data struct {
+			convolutionWrapperData
+			Kernels [][]json.RawMessage `json:"kernels"`
+			Biases  []json.RawMessage   `json:"biases"`
+		} `json:"data"`
 	}{}
 
 	err := json.Unmarshal(data, &wrap)
@@ -75,17 +86,23 @@ func (value *ConvolutionWrapper) UnmarshalJSON(data []byte) error {
 	l.OutputShape = wrap.Data.OutputShape
 	l.KernelSize = wrap.Data.KernelSize
 	l.KernelShape = wrap.Data.KernelShape
+	l.Stride = wrap.Data.Stride
+	l.Padding = wrap.Data.Padding
+	// models stored before stride was introduced always used stride 1
+	if l.Stride == 0 {
+		l.Stride = 1
+	}
 
-	l.Biases = make([]mat.Dense, len(wrap.Data.Biases))
-	for i := 0; i < len(l.Biases); i++ {
-		l.Biases[i] = wrap.Data.Biases[i].Dense
+	l.Biases, err = value.Tensors.decodeList(wrap.Data.Biases)
+	if err != nil {
+		return err
 	}
 
 	l.Kernels = make([][]mat.Dense, len(wrap.Data.Kernels))
 	for i := 0; i < len(l.Kernels); i++ {
-		l.Kernels[i] = make([]mat.Dense, len(wrap.Data.Kernels[i]))
-		for j := 0; j < len(l.Kernels[i]); j++ {
-			l.Kernels[i][j] = wrap.Data.Kernels[i][j].Dense
+		l.Kernels[i], err = value.Tensors.decodeList(wrap.Data.Kernels[i])
+		if err != nil {
+			return err
 		}
 	}
 
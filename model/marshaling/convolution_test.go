@@ -51,3 +51,23 @@ func TestConvolutionMarshaling(t *testing.T) {
 		t.Error(errors.New("mismatch in Biases"))
 	}
 }
+
+func TestConvolutionStrideMarshaling(t *testing.T) {
+	l := layer.ConvolutionLayer{}
+	l.InitializationWithOptions(layer.InputShape{Depths: 1, Width: 20, Height: 20}, 2, 3, layer.ConvolutionOptions{Stride: 2, Padding: layer.PaddingSame})
+
+	d, err := ConvolutionWrapper{ConvolutionLayer: l}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loadedWrap := ConvolutionWrapper{}
+	err = loadedWrap.UnmarshalJSON(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loadedWrap.Stride != 2 || loadedWrap.Padding != 1 {
+		t.Errorf("mismatch in Stride: %v or Padding: %v", loadedWrap.Stride, loadedWrap.Padding)
+	}
+}
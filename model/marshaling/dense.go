@@ -2,24 +2,35 @@ package marshaling
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 
 	"gonum.org/v1/gonum/mat"
 )
 
 type DenseWrapper struct {
 	mat.Dense
+	// table to store values in; values are stored as JSON without it
+	Tensors *TensorTable
 }
 
 type denseWrapperData struct {
-	Values []float64 `json:"values"`
+	Values []float64 `json:"values,omitempty"`
 	Rows   int       `json:"rows"`
 	Cols   int       `json:"cols"`
+	// index in TensorTable instead of values
+	Tensor *int `json:"tensor,omitempty"`
 }
 
 func (value DenseWrapper) MarshalJSON() ([]byte, error) {
-	values := value.Dense.RawMatrix().Data
 	rows, cols := value.Dense.Dims()
 
+	if value.Tensors != nil {
+		index := value.Tensors.add(&value.Dense)
+		return json.Marshal(denseWrapperData{Rows: rows, Cols: cols, Tensor: &index})
+	}
+
+	values := value.Dense.RawMatrix().Data
 	wrap := denseWrapperData{Values: values, Rows: rows, Cols: cols}
 
 	return json.Marshal(wrap)
@@ -31,6 +42,26 @@ func (value *DenseWrapper) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
+
+	if wrap.Tensor != nil {
+		if value.Tensors == nil {
+			return errors.New("tensor reference outside of tensor table")
+		}
+		tensor, err := value.Tensors.get(*wrap.Tensor)
+		if err != nil {
+			return err
+		}
+		if rows, cols := tensor.Dims(); rows != wrap.Rows || cols != wrap.Cols {
+			return fmt.Errorf("tensor %v is %vx%v, expected %vx%v", *wrap.Tensor, rows, cols, wrap.Rows, wrap.Cols)
+		}
+		// every tensor is referenced once, so data can be shared with the table
+		value.Dense = *tensor
+		return nil
+	}
+
+	if len(wrap.Values) != wrap.Rows*wrap.Cols {
+		return fmt.Errorf("%v values for %vx%v matrix", len(wrap.Values), wrap.Rows, wrap.Cols)
+	}
 	value.Dense = *mat.NewDense(wrap.Rows, wrap.Cols, wrap.Values)
 	return nil
 }
@@ -0,0 +1,56 @@
+package marshaling
+
+import (
+	"encoding/json"
+	"fmt"
+	"main/layer"
+	"reflect"
+)
+
+type DropoutWrapper struct {
+	layer.DropoutLayer
+}
+
+type dropoutWrapperData struct {
+	// dropout rate as passed into DropoutLayer.Initialization
+	Rate float64 `json:"rate"`
+}
+
+func (value DropoutWrapper) MarshalJSON() ([]byte, error) {
+	// DropoutLayer keeps the rate of neurons to keep
+	layerData := dropoutWrapperData{
+		Rate: 1.0 - value.Rate,
+	}
+
+	typeStr := reflect.TypeOf(value.DropoutLayer).String()
+	data := struct {
+		Type string             `json:"type"`
+		Data dropoutWrapperData `json:"data"`
+	}{
+		Type: typeStr,
+		Data: layerData,
+	}
+
+	return json.Marshal(data)
+}
+
+func (value *DropoutWrapper) UnmarshalJSON(data []byte) error {
+	wrap := struct {
+		Type string             `json:"type"`
+		Data dropoutWrapperData `json:"data"`
+	}{}
+
+	err := json.Unmarshal(data, &wrap)
+	if err != nil {
+		return err
+	}
+	if wrap.Data.Rate < 0 || wrap.Data.Rate >= 1 {
+		return fmt.Errorf("dropout rate %v is outside of [0, 1)", wrap.Data.Rate)
+	}
+
+	l := layer.DropoutLayer{}
+	l.Initialization(wrap.Data.Rate)
+
+	value.DropoutLayer = l
+	return nil
+}
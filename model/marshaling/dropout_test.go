@@ -0,0 +1,37 @@
+package marshaling
+
+import (
+	"main/layer"
+	"math"
+	"testing"
+)
+
+func TestDropoutMarshaling(t *testing.T) {
+	l := layer.DropoutLayer{}
+	l.Initialization(0.1)
+
+	d, err := DropoutWrapper{DropoutLayer: l}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loadedWrap := DropoutWrapper{}
+	err = loadedWrap.UnmarshalJSON(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(l.Rate-loadedWrap.Rate) > 1e-12 {
+		t.Errorf("mismatch in Rate: %v != %v", l.Rate, loadedWrap.Rate)
+	}
+}
+
+func TestDropoutUnmarshalingRejectsInvalidRate(t *testing.T) {
+	for _, rate := range []string{"1", "1.5", "-0.1"} {
+		loadedWrap := DropoutWrapper{}
+		err := loadedWrap.UnmarshalJSON([]byte(`{"type": "dropout", "data": {"rate": ` + rate + `}}`))
+		if err == nil {
+			t.Errorf("Expected error for rate %v", rate)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 type LayerWrapper struct {
 	layer.DenseLayer
+	// table to store weights and biases in; they are stored as JSON without it
+	Tensors *TensorTable
 }
 
 type regularizerWrapper struct {
@@ -24,8 +26,8 @@ type layerWrapperData struct {
 
 func (value LayerWrapper) MarshalJSON() ([]byte, error) {
 	layerData := layerWrapperData{
-		Weights: DenseWrapper{Dense: value.Weights},
-		Biases:  DenseWrapper{Dense: value.Biases},
+		Weights: value.Tensors.Wrap(value.Weights),
+		Biases:  value.Tensors.Wrap(value.Biases),
 		L1:      regularizerWrapper{Weight: value.L1.Weight, Bias: value.L1.Bias},
 		L2:      regularizerWrapper{Weight: value.L2.Weight, Bias: value.L2.Bias},
 		Type:    reflect.TypeOf(value.DenseLayer).String(),
@@ -43,10 +45,11 @@ func (value LayerWrapper) MarshalJSON() ([]byte, error) {
 }
 
 func (value *LayerWrapper) UnmarshalJSON(data []byte) error {
+	// wrappers created in advance resolve tensor references with the table
 	wrap := struct {
 		Type string           `json:"type"`
 		Data layerWrapperData `json:"data"`
-	}{}
+	}{Data: layerWrapperData{Weights: DenseWrapper{Tensors: value.Tensors}, Biases: DenseWrapper{Tensors: value.Tensors}}}
 
 	err := json.Unmarshal(data, &wrap)
 	if err != nil {
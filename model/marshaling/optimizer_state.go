@@ -0,0 +1,66 @@
+package marshaling
+
+import (
+	"encoding/json"
+	"fmt"
+	"main/layer"
+	"main/utils"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// OptimizerStateWrapper stores optimizer state slots (momentums, cache, etc.) by parameter name and slot name
+type OptimizerStateWrapper map[string]map[string]DenseWrapper
+
+// slots are stored in tensors, or as JSON with nil tensors
+func WrapOptimizerState(parameters []layer.Parameter, tensors *TensorTable) OptimizerStateWrapper {
+	wrap := OptimizerStateWrapper{}
+	for _, parameter := range parameters {
+		if len(parameter.State) == 0 {
+			continue
+		}
+		slots := map[string]DenseWrapper{}
+		for name, values := range parameter.State {
+			slots[name] = tensors.Wrap(*mat.DenseCopyOf(values))
+		}
+		wrap[parameter.Name] = slots
+	}
+	return wrap
+}
+
+// UnmarshalOptimizerState decodes OptimizerStateWrapper which stored its slots in tensors
+func UnmarshalOptimizerState(data []byte, tensors *TensorTable) (OptimizerStateWrapper, error) {
+	stored := map[string]map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	wrap := OptimizerStateWrapper{}
+	for parameter, slots := range stored {
+		wrap[parameter] = map[string]DenseWrapper{}
+		for name, d := range slots {
+			values, err := tensors.decode(d)
+			if err != nil {
+				return nil, err
+			}
+			wrap[parameter][name] = DenseWrapper{Dense: values}
+		}
+	}
+	return wrap, nil
+}
+
+// copies stored slots into state of parameters; parameters without stored state are left untouched
+// slots have to have the same shape as values of their parameters
+func (wrap OptimizerStateWrapper) Restore(parameters []layer.Parameter) error {
+	for _, parameter := range parameters {
+		for name, values := range wrap[parameter.Name] {
+			var lhs, rhs mat.Matrix = parameter.Values, &values.Dense
+			if !utils.CompareDims(&lhs, &rhs) {
+				return fmt.Errorf("shape mismatch for %v state of parameter: %v", name, parameter.Name)
+			}
+			parameter.State[name] = mat.DenseCopyOf(&values.Dense)
+		}
+	}
+	return nil
+}
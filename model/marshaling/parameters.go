@@ -0,0 +1,59 @@
+package marshaling
+
+import (
+	"encoding/json"
+	"fmt"
+	"main/layer"
+	"main/utils"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ParametersWrapper stores values of layer parameters by parameter name
+// it allows to persist any layer.TrainableLayer without knowing its internal structure
+type ParametersWrapper map[string]DenseWrapper
+
+// values are stored in tensors, or as JSON with nil tensors
+func WrapParameters(parameters []layer.Parameter, tensors *TensorTable) ParametersWrapper {
+	wrap := ParametersWrapper{}
+	for _, parameter := range parameters {
+		wrap[parameter.Name] = tensors.Wrap(*mat.DenseCopyOf(parameter.Values))
+	}
+	return wrap
+}
+
+// UnmarshalParameters decodes ParametersWrapper which stored its values in tensors
+func UnmarshalParameters(data []byte, tensors *TensorTable) (ParametersWrapper, error) {
+	stored := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	wrap := ParametersWrapper{}
+	for name, d := range stored {
+		values, err := tensors.decode(d)
+		if err != nil {
+			return nil, err
+		}
+		wrap[name] = DenseWrapper{Dense: values}
+	}
+	return wrap, nil
+}
+
+// copies stored values into parameters
+// parameters have to be allocated already with the same shapes as stored ones
+func (wrap ParametersWrapper) Restore(parameters []layer.Parameter) error {
+	for _, parameter := range parameters {
+		stored, ok := wrap[parameter.Name]
+		if !ok {
+			return fmt.Errorf("missing parameter: %v", parameter.Name)
+		}
+		var lhs, rhs mat.Matrix = parameter.Values, &stored.Dense
+		if !utils.CompareDims(&lhs, &rhs) {
+			return fmt.Errorf("shape mismatch for parameter: %v", parameter.Name)
+		}
+		parameter.Values.Copy(&stored.Dense)
+	}
+	return nil
+}
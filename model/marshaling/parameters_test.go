@@ -0,0 +1,43 @@
+package marshaling
+
+import (
+	"encoding/json"
+	"main/layer"
+	"testing"
+)
+
+func TestParametersMarshaling(t *testing.T) {
+	l := layer.ConvolutionLayer{}
+	l.Initialization(layer.InputShape{Depths: 2, Width: 6, Height: 6}, 3, 3)
+
+	d, err := json.Marshal(WrapParameters(l.Parameters(), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loadedWrap := ParametersWrapper{}
+	err = json.Unmarshal(d, &loadedWrap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := layer.ConvolutionLayer{}
+	loaded.Initialization(layer.InputShape{Depths: 2, Width: 6, Height: 6}, 3, 3)
+	err = loadedWrap.Restore(loaded.Parameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsEqual(l.Kernels[2][1].RawMatrix().Data, loaded.Kernels[2][1].RawMatrix().Data) {
+		t.Error("mismatch in Kernels")
+	}
+	if !IsEqual(l.Biases[1].RawMatrix().Data, loaded.Biases[1].RawMatrix().Data) {
+		t.Error("mismatch in Biases")
+	}
+
+	other := layer.ConvolutionLayer{}
+	other.Initialization(layer.InputShape{Depths: 2, Width: 6, Height: 6}, 3, 5)
+	if loadedWrap.Restore(other.Parameters()) == nil {
+		t.Error("missing error for incompatible layer")
+	}
+}
@@ -0,0 +1,57 @@
+package marshaling
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// TensorTable keeps matrices outside of JSON representation of a model
+// DenseWrapper with a table stores only index of its matrix in the table,
+// so binary formats can reuse JSON encoding of layers and write values as raw data
+// every Store and Load creates its own table; nil table means values are stored as JSON
+type TensorTable struct {
+	Tensors []mat.Dense
+}
+
+// Wrap returns DenseWrapper which stores values in the table
+func (table *TensorTable) Wrap(values mat.Dense) DenseWrapper {
+	return DenseWrapper{Dense: values, Tensors: table}
+}
+
+// decodes DenseWrapper and resolves its tensor reference with the table
+func (table *TensorTable) decode(data []byte) (mat.Dense, error) {
+	wrap := DenseWrapper{Tensors: table}
+	err := wrap.UnmarshalJSON(data)
+	return wrap.Dense, err
+}
+
+// decodes list of DenseWrapper
+func (table *TensorTable) decodeList(list []json.RawMessage) ([]mat.Dense, error) {
+	result := make([]mat.Dense, len(list))
+	for i, data := range list {
+		var err error
+		result[i], err = table.decode(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (table *TensorTable) add(values *mat.Dense) int {
+	tensor := mat.Dense{}
+	if !values.IsEmpty() {
+		tensor.CloneFrom(values)
+	}
+	table.Tensors = append(table.Tensors, tensor)
+	return len(table.Tensors) - 1
+}
+
+func (table *TensorTable) get(index int) (*mat.Dense, error) {
+	if index < 0 || index >= len(table.Tensors) {
+		return nil, fmt.Errorf("missing tensor: %v", index)
+	}
+	return &table.Tensors[index], nil
+}
@@ -0,0 +1,53 @@
+package marshaling
+
+import (
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestDenseMarshalingWithTensorTable(t *testing.T) {
+	arr := []float64{10.0, 1e-5, 0.1, 4.0}
+	table := TensorTable{}
+	testValue := table.Wrap(*mat.NewDense(2, 2, arr))
+
+	d, err := testValue.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(d), "values") || len(table.Tensors) != 1 {
+		t.Fatalf("values are not moved into table: %v", string(d))
+	}
+
+	// reference can't be resolved without table
+	loaded := DenseWrapper{}
+	err = loaded.UnmarshalJSON(d)
+	if err == nil {
+		t.Fatal("Expected error for missing tensor table")
+	}
+
+	loaded = DenseWrapper{Tensors: &table}
+	err = loaded.UnmarshalJSON(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEqual(arr, loaded.RawMatrix().Data) {
+		t.Error("different values")
+	}
+}
+
+func TestDenseMarshalingRejectsMismatchedShape(t *testing.T) {
+	table := TensorTable{Tensors: []mat.Dense{*mat.NewDense(2, 3, nil)}}
+	loaded := DenseWrapper{Tensors: &table}
+	err := loaded.UnmarshalJSON([]byte(`{"rows": 3, "cols": 2, "tensor": 0}`))
+	if err == nil {
+		t.Fatal("Expected error for tensor of different shape")
+	}
+
+	loaded = DenseWrapper{}
+	err = loaded.UnmarshalJSON([]byte(`{"values": [1, 2, 3], "rows": 2, "cols": 2}`))
+	if err == nil {
+		t.Fatal("Expected error for wrong number of values")
+	}
+}
@@ -2,13 +2,18 @@ package model
 
 import (
 	"fmt"
+	"log"
 	"main/accuracy"
 	"main/activation"
 	"main/layer"
 	"main/loss"
 	"main/optimizer"
-	"main/utils"
+	"main/scaler"
+	"main/scheduler"
+	"time"
 
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -19,6 +24,22 @@ type Model struct {
 	Optimizer optimizer.OptimizerInterface
 	Accuracy  accuracy.AccuracyInterface
 
+	// fitted scaler applied to inputs of Train, Evaluate and Predict, so raw samples are passed to all of them
+	// it's stored together with the model; nil passes inputs unchanged
+	Scaler scaler.ScalerInterface
+
+	// receives progress of training and evaluation; nothing is printed if nil
+	Reporter Reporter
+
+	// seed of random numbers used by layers during training, e.g. dropout masks
+	Seed uint64
+
+	// shared by every layer.RandomLayer; its state is stored in checkpoints
+	source rand.PCGSource
+
+	// set by StopTraining
+	stopTraining bool
+
 	inputLayer            layer.InputLayer
 	outputLayerActivation activation.ActivationInterface
 }
@@ -38,9 +59,9 @@ func (m *Model) Set(loss loss.LossInterface, optimizer optimizer.OptimizerInterf
 }
 
 func (m *Model) passTrainableLayer() {
-	trainableLayers := make([]*layer.DenseLayer, 0)
+	trainableLayers := make([]layer.TrainableLayer, 0)
 	for _, item := range m.Layers {
-		layer, ok := item.(*layer.DenseLayer)
+		layer, ok := item.(layer.TrainableLayer)
 		if ok {
 			trainableLayers = append(trainableLayers, layer)
 		}
@@ -53,6 +74,13 @@ func (m *Model) Finalize() {
 
 	m.passTrainableLayer()
 
+	m.source.Seed(m.Seed)
+	for _, item := range m.Layers {
+		if randomLayer, ok := item.(layer.RandomLayer); ok {
+			randomLayer.SetRandomSource(&m.source)
+		}
+	}
+
 	// TODO: check is it's referenced or copied?
 	// if yes - is it an issue?
 	lastLayer := m.Layers[len(m.Layers)-1]
@@ -76,6 +104,13 @@ func (m *Model) Description() {
 }
 
 func (m *Model) Forward(input mat.Dense, isTraining bool) *mat.Dense {
+	if m.Scaler != nil {
+		scaled, err := m.Scaler.Transform(&input)
+		if err != nil {
+			log.Fatalf("Scaler can't transform inputs: %v", err)
+		}
+		input = *scaled
+	}
 	m.inputLayer.Forward(&input, isTraining)
 
 	for i, layer := range m.Layers {
@@ -102,114 +137,278 @@ func (m *Model) Backward(output mat.Dense, target mat.Dense) {
 	}
 }
 
-func (m *Model) Train(trainingData ModelData, epochs int, batchSize *int, printEvery int, validationData *ModelData) {
-	fmt.Println("================================")
-	fmt.Println(m.Name, "Training")
-	m.Accuracy.Initialization(&trainingData.Y)
+// TrainOption configures optional behaviour of Model.Train
+type TrainOption func(options *trainOptions)
+
+type trainOptions struct {
+	resumePath      string
+	checkpointPath  string
+	checkpointEvery int
+	recordSteps     bool
+	validateEvery   int
+	shuffle         bool
+	shuffleSeed     uint64
+	callbacks       []Callback
+	clipValue       float64
+	clipNorm        float64
+	accumulate      int
+}
 
-	// default value if batch size is nil
-	trainSteps := 1
+// ResumeFrom continues training from a checkpoint stored by Model.SaveCheckpoint
+func ResumeFrom(path string) TrainOption {
+	return func(options *trainOptions) {
+		options.resumePath = path
+	}
+}
 
-	// calculate steps based on data lenght and batch size
-	if batchSize != nil {
-		trainSteps = calculateSteps(trainingData, *batchSize)
+// CheckpointEvery stores a checkpoint at path after every n epochs
+func CheckpointEvery(n int, path string) TrainOption {
+	return func(options *trainOptions) {
+		options.checkpointEvery = n
+		options.checkpointPath = path
 	}
+}
 
-	for epoch := 0; epoch < epochs+1; epoch++ {
-		fmt.Println(m.Name, "Epoch", epoch)
+// RecordSteps adds metrics of every training step into History.Steps
+func RecordSteps() TrainOption {
+	return func(options *trainOptions) {
+		options.recordSteps = true
+	}
+}
 
-		m.Loss.ResetAccumulated()
-		m.Accuracy.ResetAccumulated()
+// ValidateEvery evaluates validation data passed to Model.Train or Model.TrainWithLoader after every n epochs
+// results are stored in EpochRecord.Validation and observed by schedulers driven by metrics, e.g. ReduceOnPlateau,
+// which require it when validation data is passed
+func ValidateEvery(n int) TrainOption {
+	return func(options *trainOptions) {
+		options.validateEvery = n
+	}
+}
 
-		for _, step := range utils.MakeRange(trainSteps) {
-			batchX, batchY := makeBatch(trainingData, step, batchSize)
-			output := m.Forward(batchX, true)
+// Shuffle makes Model.Train shuffle training data at the beginning of every epoch
+// Model.TrainWithLoader uses loaders as they are configured
+func Shuffle(seed uint64) TrainOption {
+	return func(options *trainOptions) {
+		options.shuffle = true
+		options.shuffleSeed = seed
+	}
+}
 
-			dataLoss := loss.CalculateLoss(m.Loss, output, &batchY)
-			regularizationLoss := m.Loss.RegularizationLoss()
-			lossValue := dataLoss + regularizationLoss
+// trains the model and returns collected metrics
+// progress is passed to m.Reporter; printEvery defines how often steps are reported
+func (m *Model) Train(trainingData ModelData, epochs int, batchSize *int, printEvery int, validationData *ModelData, opts ...TrainOption) *History {
+	options := trainOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
 
-			predictions := m.outputLayerActivation.Predictions(output)
-			accuracy := accuracy.CalculateAccuracy(m.Accuracy, &predictions, &batchY)
+	loader := NewMemoryLoader(trainingData, batchSize)
+	loader.Shuffle = options.shuffle
+	loader.Seed = options.shuffleSeed
 
-			m.passTrainableLayer()
+	var validationLoader DataLoader
+	if validationData != nil {
+		validationLoader = NewMemoryLoader(*validationData, batchSize)
+	}
+	return m.TrainWithLoader(loader, epochs, printEvery, validationLoader, opts...)
+}
 
-			m.Backward(*output, batchY)
+// trains the model on batches of loader; validation is optional and can be nil
+func (m *Model) TrainWithLoader(loader DataLoader, epochs int, printEvery int, validation DataLoader, opts ...TrainOption) *History {
+	options := trainOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
 
-			m.Optimizer.PreUpdate()
-			for _, item := range m.Layers {
-				denseLayer, ok := item.(*layer.DenseLayer)
-				if ok {
-					m.Optimizer.UpdateParams(denseLayer)
-				}
+	state := TrainingState{}
+	if options.resumePath != "" {
+		var err error
+		state, err = m.LoadCheckpoint(options.resumePath)
+		if err != nil {
+			log.Fatalf("Failed to resume training: %v", err)
+		}
+	}
+
+	if scheduled, ok := m.Optimizer.(optimizer.ScheduledOptimizer); ok && scheduled.GetScheduler() != nil {
+		if err := scheduler.Validate(scheduled.GetScheduler()); err != nil {
+			log.Fatalf("Invalid learning rate scheduler: %v", err)
+		}
+		// otherwise validation data is evaluated only after training and the scheduler would silently follow training loss
+		if _, ok := scheduled.GetScheduler().(scheduler.MetricScheduler); ok && validation != nil && options.validateEvery == 0 {
+			log.Fatalf("Learning rate scheduler %v with validation data requires ValidateEvery", scheduled.GetScheduler().Name())
+		}
+	}
+
+	history := &History{}
+	trainStart := time.Now()
+
+	m.stopTraining = false
+	if m.Reporter != nil {
+		m.Reporter.TrainBegin(m)
+	}
+	for _, callback := range options.callbacks {
+		callback.OnTrainBegin(m)
+	}
+	m.initializeAccuracy(loader)
+	trainSteps := loader.Steps()
+	if trainSteps > 0 && options.accumulate > 1 {
+		trainSteps = (trainSteps + options.accumulate - 1) / options.accumulate
+	}
 
-				convLayer, ok := item.(*layer.ConvolutionLayer)
-				if ok {
-					convLayer.UpdateParams(m.Optimizer.GetCurrentLearningRate())
-				}
+	for epoch := state.Epoch; epoch < epochs+1; epoch++ {
+		epochStart := time.Now()
+		if m.Reporter != nil {
+			m.Reporter.EpochBegin(m, epoch)
+		}
+		for _, callback := range options.callbacks {
+			callback.OnEpochBegin(m, epoch)
+		}
+
+		m.Loss.ResetAccumulated()
+		m.Accuracy.ResetAccumulated()
+
+		// mean gradient norm of the epoch
+		epochGradientNorm, steps := 0.0, 0
+
+		loader.Begin(epoch)
+		for step := 0; ; step++ {
+			batches := nextBatches(loader, max(options.accumulate, 1))
+			if len(batches) == 0 {
+				break
+			}
+			steps += 1
+			for _, callback := range options.callbacks {
+				callback.OnBatchBegin(m, epoch, step)
 			}
 
-			m.Optimizer.PostUpdate()
+			record := m.trainStep(batches, options)
+			record.Epoch = epoch
+			record.Step = step
+			state.Step += 1
+			epochGradientNorm += record.GradientNorm
 
-			if step%printEvery == 0 || step == trainSteps-1 {
-				fmt.Println(m.Name, "step:", step, "\n",
-					"loss:", lossValue,
-					"(data loss:", dataLoss, "reg loss:", regularizationLoss, ") ",
-					"acc:", accuracy,
-					"lr", m.Optimizer.GetCurrentLearningRate())
+			if options.recordSteps {
+				history.Steps = append(history.Steps, record)
+			}
+			if m.Reporter != nil && ((printEvery > 0 && step%printEvery == 0) || step == trainSteps-1) {
+				m.Reporter.StepEnd(m, record)
+			}
+			for _, callback := range options.callbacks {
+				callback.OnBatchEnd(m, record)
 			}
 		}
 
+		checkLoader(loader)
+
 		epochDataLoss := m.Loss.CalculateAccumulatedLoss()
 		epochRegularisationLoss := m.Loss.RegularizationLoss()
-		epochLoss := epochDataLoss + epochRegularisationLoss
-		epochAccuracy := m.Accuracy.CalculateAccumulatedAccuracy()
-		fmt.Println(m.Name, "training, ",
-			"loss:", epochLoss,
-			"(data_loss:", epochDataLoss, "reg_loss:", epochRegularisationLoss, ") ",
-			"acc:", epochAccuracy,
-			"lr", m.Optimizer.GetCurrentLearningRate())
+		record := EpochRecord{
+			Epoch:              epoch,
+			Loss:               epochDataLoss + epochRegularisationLoss,
+			DataLoss:           epochDataLoss,
+			RegularizationLoss: epochRegularisationLoss,
+			Accuracy:           m.Accuracy.CalculateAccumulatedAccuracy(),
+			LearningRate:       m.Optimizer.GetCurrentLearningRate(),
+			GradientNorm:       epochGradientNorm / float64(max(steps, 1)),
+		}
+		if validation != nil && options.validateEvery > 0 && (epoch+1)%options.validateEvery == 0 {
+			evaluation := m.EvaluateWithLoader(validation)
+			record.Validation = &evaluation
+		}
+		m.observeScheduler(record, validation != nil && options.validateEvery > 0)
+		record.Duration = time.Since(epochStart)
+		history.Epochs = append(history.Epochs, record)
+		if m.Reporter != nil {
+			m.Reporter.EpochEnd(m, record)
+		}
+		for _, callback := range options.callbacks {
+			callback.OnEpochEnd(m, record)
+		}
+
+		state.Epoch = epoch + 1
+		if options.checkpointEvery > 0 && state.Epoch%options.checkpointEvery == 0 {
+			err := m.SaveCheckpoint(options.checkpointPath, state)
+			if err != nil {
+				log.Fatalf("Failed to store checkpoint: %v", err)
+			}
+		}
+
+		if m.stopTraining {
+			break
+		}
 	}
 
-	if validationData != nil {
-		m.Evaluate(*validationData, batchSize)
+	if validation != nil {
+		evaluation := m.EvaluateWithLoader(validation)
+		history.Validation = &evaluation
 	}
-}
 
-func (m *Model) Evaluate(data ModelData, batchSize *int) {
-	fmt.Println(m.Name, "Evaluation")
-	validationSteps := 1
-	if batchSize != nil {
-		validationSteps = calculateSteps(data, *batchSize)
+	history.Duration = time.Since(trainStart)
+	for _, callback := range options.callbacks {
+		callback.OnTrainEnd(m, history)
 	}
+	return history
+}
 
-	m.Loss.RegularizationLoss()
-	m.Accuracy.ResetAccumulated()
+// returns loss and accuracy of the model on data
+func (m *Model) Evaluate(data ModelData, batchSize *int) Evaluation {
+	return m.EvaluateWithLoader(NewMemoryLoader(data, batchSize))
+}
 
-	for _, step := range utils.MakeRange(validationSteps) {
-		batchX, batchY := makeBatch(data, step, batchSize)
-		validationOutput := m.Forward(batchX, false)
+// returns loss and accuracy of the model on all batches of loader
+func (m *Model) EvaluateWithLoader(loader DataLoader) Evaluation {
+	// accumulated locally, so accumulators of training are not affected
+	lossSum, lossCount := 0.0, 0
+	accuracySum, accuracyCount := 0.0, int64(0)
+
+	loader.Begin(0)
+	for {
+		batch, ok := loader.Next()
+		if !ok {
+			break
+		}
+		validationOutput := m.Forward(batch.X, false)
 
-		loss.CalculateLoss(m.Loss, validationOutput, &batchY)
+		sampleLosses := m.Loss.Forward(validationOutput, &batch.Y)
+		lossSum += floats.Sum(sampleLosses)
+		lossCount += len(sampleLosses)
 
 		validationPredictions := m.outputLayerActivation.Predictions(validationOutput)
-		accuracy.CalculateAccuracy(m.Accuracy, &validationPredictions, &batchY)
+		sum, count := accuracy.CountCorrect(m.Accuracy, &validationPredictions, &batch.Y)
+		accuracySum += sum
+		accuracyCount += count
 	}
-	valLoss := m.Loss.CalculateAccumulatedLoss()
-	valAccuracy := m.Accuracy.CalculateAccumulatedAccuracy()
-	fmt.Println(m.Name, "validation:", "loss:", valLoss, "accuracy:", valAccuracy)
+	checkLoader(loader)
+
+	// empty loader gives zero loss and accuracy instead of NaN
+	result := Evaluation{}
+	if lossCount > 0 {
+		result.Loss = lossSum / float64(lossCount)
+	}
+	if accuracyCount > 0 {
+		result.Accuracy = accuracySum / float64(accuracyCount)
+	}
+	if m.Reporter != nil {
+		m.Reporter.Evaluation(m, result)
+	}
+	return result
 }
 
 func (m *Model) Predict(inputSamples *mat.Dense, batchSize *int) mat.Dense {
-	predictionSteps := 1
-	if batchSize != nil {
-		predictionSteps = calculateSteps(ModelData{X: *inputSamples, Y: *inputSamples}, *batchSize)
-	}
+	return m.PredictWithLoader(NewMemoryLoader(ModelData{X: *inputSamples}, batchSize))
+}
 
+// returns outputs of the model for all batches of loader; targets of batches are ignored
+func (m *Model) PredictWithLoader(loader DataLoader) mat.Dense {
 	var output *mat.Dense
-	for _, step := range utils.MakeRange(predictionSteps) {
-		batchX, _ := makeBatch(ModelData{X: *inputSamples, Y: *inputSamples}, step, batchSize)
-		validationOutput := m.Forward(batchX, false)
+
+	loader.Begin(0)
+	for {
+		batch, ok := loader.Next()
+		if !ok {
+			break
+		}
+		validationOutput := m.Forward(batch.X, false)
 
 		if output == nil {
 			// init output from first response
@@ -222,41 +421,101 @@ func (m *Model) Predict(inputSamples *mat.Dense, batchSize *int) mat.Dense {
 			tmp.Stack(output, validationOutput)
 			output = tmp
 		}
-
 	}
+	checkLoader(loader)
 
+	if output == nil {
+		return mat.Dense{}
+	}
 	return *output
 }
 
-func calculateSteps(data ModelData, batchSize int) int {
-	count, _ := data.X.Dims()
-	steps := count / batchSize
-	if steps*batchSize < count {
-		steps += 1
-	}
-	return steps
-}
+// runs forward and backward pass of every micro-batch and one optimizer step with their combined gradients
+func (m *Model) trainStep(batches []ModelData, options trainOptions) StepRecord {
+	accumulator := gradientAccumulator{}
+	dataLosses := make([]float64, len(batches))
+	accuracies := make([]float64, len(batches))
+	samples := make([]float64, len(batches))
+
+	for i, batch := range batches {
+		output := m.Forward(batch.X, true)
+		dataLosses[i] = loss.CalculateLoss(m.Loss, output, &batch.Y)
 
-func makeBatch(data ModelData, step int, batchSize *int) (mat.Dense, mat.Dense) {
-	var batchX mat.Dense
-	var batchY mat.Dense
-	if batchSize == nil {
-		batchX = data.X
-		batchY = data.Y
-	} else {
-		rows, cols := data.X.Dims()
-		targetRowIndex := (step + 1) * *batchSize
-		if targetRowIndex > rows {
-			targetRowIndex = rows - 1
+		predictions := m.outputLayerActivation.Predictions(output)
+		accuracies[i] = accuracy.CalculateAccuracy(m.Accuracy, &predictions, &batch.Y)
+
+		m.passTrainableLayer()
+
+		m.Backward(*output, batch.Y)
+
+		rows, _ := batch.X.Dims()
+		samples[i] = float64(rows)
+		if len(batches) > 1 {
+			accumulator.add(m.Layers, rows)
 		}
-		batchX = *mat.DenseCopyOf(data.X.Slice(step**batchSize, targetRowIndex, 0, cols))
+	}
+	if len(batches) > 1 {
+		accumulator.apply(m.Layers)
+	}
+
+	record := StepRecord{
+		DataLoss:           weightedMean(dataLosses, samples),
+		RegularizationLoss: m.Loss.RegularizationLoss(),
+		Accuracy:           weightedMean(accuracies, samples),
+		GradientNorm:       clipGradients(m.Layers, options.clipValue, options.clipNorm),
+	}
+	record.Loss = record.DataLoss + record.RegularizationLoss
 
-		rows, cols = data.Y.Dims()
-		targetRowIndex = (step + 1) * *batchSize
-		if targetRowIndex > rows {
-			targetRowIndex = rows - 1
+	m.Optimizer.PreUpdate()
+	for _, item := range m.Layers {
+		trainableLayer, ok := item.(layer.TrainableLayer)
+		if ok {
+			m.Optimizer.UpdateParams(trainableLayer)
 		}
-		batchY = *mat.DenseCopyOf(data.Y.Slice(step**batchSize, targetRowIndex, 0, cols))
 	}
-	return batchX, batchY
+
+	m.Optimizer.PostUpdate()
+
+	record.LearningRate = m.Optimizer.GetCurrentLearningRate()
+	return record
+}
+
+// passes validation loss to schedulers driven by metrics; epochs without validation are skipped when validation is configured,
+// so the scheduler compares the same metric. Training loss is used only for training without validation data
+func (m *Model) observeScheduler(record EpochRecord, validated bool) {
+	scheduled, ok := m.Optimizer.(optimizer.ScheduledOptimizer)
+	if !ok {
+		return
+	}
+	metricScheduler, ok := scheduled.GetScheduler().(scheduler.MetricScheduler)
+	if !ok {
+		return
+	}
+	if record.Validation != nil {
+		metricScheduler.Observe(record.Validation.Loss)
+	} else if !validated {
+		metricScheduler.Observe(record.Loss)
+	}
+}
+
+// training can't continue with missing batches
+func checkLoader(loader DataLoader) {
+	if err := loader.Err(); err != nil {
+		log.Fatalf("Failed to load batch: %v", err)
+	}
+}
+
+// accuracy can depend on all targets, e.g. precision of RegressionAccuracy
+// loaders which don't keep all samples in memory provide targets of their first batch
+func (m *Model) initializeAccuracy(loader DataLoader) {
+	if memoryLoader, ok := loader.(*MemoryLoader); ok {
+		m.Accuracy.Initialization(&memoryLoader.Data.Y)
+		return
+	}
+
+	loader.Begin(0)
+	batch, ok := loader.Next()
+	if ok {
+		m.Accuracy.Initialization(&batch.Y)
+	}
 }
@@ -4,15 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
-	"log"
-	"main/accuracy"
-	"main/activation"
-	"main/layer"
-	"main/loss"
 	"main/model/marshaling"
+	"main/optimizations"
 	"main/optimizer"
+	"main/scheduler"
 	"os"
-	"reflect"
 )
 
 type ModelDataProvider interface {
@@ -30,74 +26,11 @@ func (provider *JSONModelDataProvider) Store(path string, model *Model) error {
 	}
 	defer file.Close()
 
-	layersWraps := make([]interface{}, 0)
-	for _, item := range model.Layers {
-		var l *layer.DenseLayer = &layer.DenseLayer{}
-		var relu *activation.Activation_ReLU = &activation.Activation_ReLU{}
-		var softmax *activation.SoftmaxActivation = &activation.SoftmaxActivation{}
-		var sigmoid *activation.SigmoidActivation = &activation.SigmoidActivation{}
-		var convolution *layer.ConvolutionLayer = &layer.ConvolutionLayer{}
-		var maxPooling *layer.MaxPoolingLayer = &layer.MaxPoolingLayer{}
-
-		if reflect.TypeOf(item).String() == reflect.TypeOf(l).String() {
-			l, _ := item.(*layer.DenseLayer)
-			layersWraps = append(layersWraps, marshaling.LayerWrapper{DenseLayer: *l})
-		} else if reflect.TypeOf(item).String() == reflect.TypeOf(convolution).String() {
-			convolutionLayer, _ := item.(*layer.ConvolutionLayer)
-			layersWraps = append(layersWraps, marshaling.ConvolutionWrapper{ConvolutionLayer: *convolutionLayer})
-		} else if reflect.TypeOf(item).String() == reflect.TypeOf(relu).String() {
-			v := struct {
-				Type string `json:"type"`
-			}{
-				Type: reflect.TypeOf(item).String(),
-			}
-			layersWraps = append(layersWraps, v)
-		} else if reflect.TypeOf(item).String() == reflect.TypeOf(softmax).String() {
-			v := struct {
-				Type string `json:"type"`
-			}{
-				Type: reflect.TypeOf(item).String(),
-			}
-			layersWraps = append(layersWraps, v)
-		} else if reflect.TypeOf(item).String() == reflect.TypeOf(sigmoid).String() {
-			v := struct {
-				Type string `json:"type"`
-			}{
-				Type: reflect.TypeOf(item).String(),
-			}
-			layersWraps = append(layersWraps, v)
-		} else if reflect.TypeOf(item).String() == reflect.TypeOf(maxPooling).String() {
-			maxPoolingLayer, _ := item.(*layer.MaxPoolingLayer)
-			layersWraps = append(layersWraps, marshaling.MaxPoolingWrapper{MaxPoolingLayer: *maxPoolingLayer})
-		} else {
-			log.Fatalf("Unknown type: %v", reflect.TypeOf(item).String())
-		}
-	}
-
-	o := struct {
-		Type string      `json:"type"`
-		Data interface{} `json:"data"`
-	}{
-		Type: reflect.TypeOf(model.Optimizer).String(),
-		Data: model.Optimizer,
-	}
-
-	root := struct {
-		Name      string        `json:"name"`
-		Layers    []interface{} `json:"layers"`
-		Loss      string        `json:"loss"`
-		Accuracy  string        `json:"accuracy"`
-		Optimizer interface{}   `json:"optimizer"`
-	}{
-		Name:      model.Name,
-		Layers:    layersWraps,
-		Loss:      reflect.TypeOf(model.Loss).String(),
-		Accuracy:  reflect.TypeOf(model.Accuracy).String(),
-		Optimizer: o,
+	d, err := encodeModel(model, nil)
+	if err != nil {
+		return err
 	}
 
-	d, err := json.Marshal(root)
-
 	_, err = file.Write(d)
 	if err != nil {
 		return err
@@ -105,6 +38,7 @@ func (provider *JSONModelDataProvider) Store(path string, model *Model) error {
 
 	return nil
 }
+
 func (provider *JSONModelDataProvider) Load(path string) (*Model, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -117,134 +51,149 @@ func (provider *JSONModelDataProvider) Load(path string) (*Model, error) {
 		return nil, err
 	}
 
-	dict := map[string]interface{}{}
-	json.Unmarshal(data, &dict)
-
-	m := Model{}
-	name, _ := dict["name"].(string)
-	m.Name = name
-
-	layers, ok := dict["layers"].([]interface{})
-	if ok {
-		for _, l := range layers {
-			layerData, ok := l.(map[string]interface{})
-			if ok {
-				// decode layer.Layer
-				if layerData["type"] == reflect.TypeOf(layer.DenseLayer{}).String() {
-					layerWrap := marshaling.LayerWrapper{}
-					bd, err := json.Marshal(l)
-					if err != nil {
-						return nil, err
-					}
-					err = json.Unmarshal(bd, &layerWrap)
-					layer := layer.DenseLayer{}
-					layer.LoadFromParams(&layerWrap.Weights, &layerWrap.Biases, layerWrap.L1, layerWrap.L2)
-					m.Add(&layer)
-				}
-
-				// decode layer.ConvolutionLayer
-				if layerData["type"] == reflect.TypeOf(layer.ConvolutionLayer{}).String() {
-					layerWrap := marshaling.ConvolutionWrapper{}
-					bd, err := json.Marshal(l)
-					if err != nil {
-						return nil, err
-					}
-					err = json.Unmarshal(bd, &layerWrap)
-					layer := layer.ConvolutionLayer{}
-					layer.LoadFromParams(layerWrap.InputShape, layerWrap.Depths, layerWrap.KernelSize, layerWrap.OutputShape, layerWrap.KernelShape, layerWrap.Kernels, layerWrap.Biases)
-					m.Add(&layer)
-				}
-				// decode activation.Activation_ReLU
-
-				// decode layer.MaxPoolingLayer
-				if layerData["type"] == reflect.TypeOf(layer.MaxPoolingLayer{}).String() {
-					layerWrap := marshaling.MaxPoolingWrapper{}
-					bd, err := json.Marshal(l)
-					if err != nil {
-						return nil, err
-					}
-					err = json.Unmarshal(bd, &layerWrap)
-					layer := layer.MaxPoolingLayer{}
-					layer.LoadFromParams(layerWrap.PoolSize, layerWrap.InputShape, layerWrap.OutputShape)
-					m.Add(&layer)
-				}
-
-				var relu *activation.Activation_ReLU = &activation.Activation_ReLU{}
-				if layerData["type"] == reflect.TypeOf(relu).String() {
-					a := activation.Activation_ReLU{}
-					m.Add(&a)
-				}
-
-				// decode SoftmaxActivation
-				var softmax *activation.SoftmaxActivation = &activation.SoftmaxActivation{}
-				if layerData["type"] == reflect.TypeOf(softmax).String() {
-					a := activation.SoftmaxActivation{}
-					m.Add(&a)
-				}
-
-				// decode SigmoidActivation
-				var sigmoid *activation.SigmoidActivation = &activation.SigmoidActivation{}
-				if layerData["type"] == reflect.TypeOf(sigmoid).String() {
-					a := activation.SigmoidActivation{}
-					m.Add(&a)
-				}
-			} else {
-				return nil, errors.New("failed to typecase layerData")
-			}
+	return decodeModel(data, nil)
+}
+
+// encodes model into JSON document with registered type names
+// matrices are stored in tensors by binary files; nil tensors store them in the document
+func encodeModel(model *Model, tensors *marshaling.TensorTable) ([]byte, error) {
+	layers := make([]typedValue, 0, len(model.Layers))
+	for _, item := range model.Layers {
+		value, err := LayerRegistry.encode(item, tensors)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		return nil, errors.New("failed to get layers")
+		layers = append(layers, value)
+	}
+
+	lossValue, err := LossRegistry.encode(model.Loss, tensors)
+	if err != nil {
+		return nil, err
+	}
+	accuracyValue, err := AccuracyRegistry.encode(model.Accuracy, tensors)
+	if err != nil {
+		return nil, err
+	}
+	optimizerValue, err := OptimizerRegistry.encode(model.Optimizer, tensors)
+	if err != nil {
+		return nil, err
+	}
+
+	// models without scaler are stored without the field
+	var scalerValue *typedValue
+	if model.Scaler != nil {
+		value, err := ScalerRegistry.encode(model.Scaler, tensors)
+		if err != nil {
+			return nil, err
+		}
+		scalerValue = &value
 	}
 
-	lossString, _ := dict["loss"].(string)
-	var lossValue loss.LossInterface
-	switch lossString {
-	case reflect.TypeOf(&loss.CategoricalCrossentropyLoss{}).String():
-		lossValue = &loss.CategoricalCrossentropyLoss{}
-	default:
-		return nil, errors.New("Unsupported loss")
+	// optimizers are stored by encoding/json, so their scheduler is stored separately
+	var schedulerValue *typedValue
+	if scheduled, ok := model.Optimizer.(optimizer.ScheduledOptimizer); ok && scheduled.GetScheduler() != nil {
+		value, err := SchedulerRegistry.encode(scheduled.GetScheduler(), tensors)
+		if err != nil {
+			return nil, err
+		}
+		schedulerValue = &value
 	}
 
-	accuracyString, _ := dict["accuracy"].(string)
-	var accuracyValue accuracy.AccuracyInterface
-	switch accuracyString {
-	case reflect.TypeOf(&accuracy.CategorialAccuracy{}).String():
-		accuracyValue = &accuracy.CategorialAccuracy{}
-	default:
-		return nil, errors.New("Unsupported loss")
+	root := struct {
+		Name      string       `json:"name"`
+		Layers    []typedValue `json:"layers"`
+		Loss      typedValue   `json:"loss"`
+		Accuracy  typedValue   `json:"accuracy"`
+		Optimizer typedValue   `json:"optimizer"`
+		Scaler    *typedValue  `json:"scaler,omitempty"`
+		Scheduler *typedValue  `json:"scheduler,omitempty"`
+	}{
+		Name:      model.Name,
+		Layers:    layers,
+		Loss:      lossValue,
+		Accuracy:  accuracyValue,
+		Optimizer: optimizerValue,
+		Scaler:    scalerValue,
+		Scheduler: schedulerValue,
 	}
 
-	var optimizerValue optimizer.OptimizerInterface
-	optimizerDict, ok := dict["optimizer"].(map[string]interface{})
-	if !ok {
-		return nil, errors.New("cannot get optimizer data")
+	return json.Marshal(root)
+}
+
+// creates model from JSON document produced by encodeModel with the same tensors
+func decodeModel(data []byte, tensors *marshaling.TensorTable) (*Model, error) {
+	root := struct {
+		Name      string        `json:"name"`
+		Layers    *[]typedValue `json:"layers"`
+		Loss      typedValue    `json:"loss"`
+		Accuracy  typedValue    `json:"accuracy"`
+		Optimizer typedValue    `json:"optimizer"`
+		Scaler    *typedValue   `json:"scaler"`
+		Scheduler *typedValue   `json:"scheduler"`
+	}{}
+	err := json.Unmarshal(data, &root)
+	if err != nil {
+		return nil, err
 	}
-	optimizerType := optimizerDict["type"].(string)
-	switch optimizerType {
-	case reflect.TypeOf(&optimizer.OptimizerAdam{}).String():
-		d, err := json.Marshal(optimizerDict["data"])
+	if root.Layers == nil {
+		return nil, errors.New("failed to get layers")
+	}
+
+	m := Model{Name: root.Name}
+	for _, value := range *root.Layers {
+		l, err := LayerRegistry.decode(value, tensors)
 		if err != nil {
 			return nil, err
 		}
-		wrap := optimizer.OptimizerAdam{}
-		err = json.Unmarshal(d, &wrap)
+		m.Add(l)
+	}
+
+	lossValue, err := LossRegistry.decode(root.Loss, tensors)
+	if err != nil {
+		return nil, err
+	}
+	accuracyValue, err := AccuracyRegistry.decode(root.Accuracy, tensors)
+	if err != nil {
+		return nil, err
+	}
+	optimizerValue, err := OptimizerRegistry.decode(root.Optimizer, tensors)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.Scheduler != nil {
+		scheduled, ok := optimizerValue.(optimizer.ScheduledOptimizer)
+		if !ok {
+			return nil, errors.New("optimizer doesn't support schedulers")
+		}
+		s, err := SchedulerRegistry.decode(*root.Scheduler, tensors)
 		if err != nil {
 			return nil, err
 		}
-		optimizerValue = &wrap
-	case reflect.TypeOf(&optimizer.OptimizerSGD{}).String():
-		d, err := json.Marshal(optimizerDict["data"])
+		err = scheduler.Validate(s)
 		if err != nil {
 			return nil, err
 		}
-		wrap := optimizer.OptimizerSGD{}
-		err = json.Unmarshal(d, &wrap)
+		scheduled.SetScheduler(s)
+	}
+
+	if root.Scaler != nil {
+		m.Scaler, err = ScalerRegistry.decode(*root.Scaler, tensors)
 		if err != nil {
 			return nil, err
 		}
-		optimizerValue = &wrap
-	default:
-		return nil, errors.New("unsupported optimizer")
+	}
+
+	// optimized softmax activation and loss have to share backward implementation
+	if optimizedLoss, ok := lossValue.(*optimizations.OptimizedCategoricalCrossentropyLoss); ok {
+		var optimizedActivation *optimizations.OptimizedSoftmaxActivation
+		if len(m.Layers) > 0 {
+			optimizedActivation, _ = m.Layers[len(m.Layers)-1].(*optimizations.OptimizedSoftmaxActivation)
+		}
+		if optimizedActivation == nil {
+			return nil, errors.New("optimized loss requires optimized softmax activation as the last layer")
+		}
+		optimizations.LinkOptimizedCategorialCrossentropy(optimizedActivation, optimizedLoss)
 	}
 
 	m.Set(lossValue, optimizerValue, accuracyValue)
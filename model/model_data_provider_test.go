@@ -0,0 +1,190 @@
+package model_test
+
+import (
+	"main/accuracy"
+	"main/activation"
+	"main/layer"
+	"main/loss"
+	"main/model"
+	"main/optimizations"
+	"main/optimizer"
+	"main/scaler"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestJSONModelDataProviderRoundTrip(t *testing.T) {
+	ada := optimizer.NewAda(0.1, 1e-3, 1e-7)
+	ada.Iterations = 12
+	rmsprop := optimizer.NewRMSprop(0.02, 1e-4, 1e-7, 0.9)
+	sgd := optimizer.NewSGD(0.5, 1e-3, 0.9)
+	adam := optimizer.NewAdam()
+
+	cases := []struct {
+		activation layer.LayerInterface
+		loss       loss.LossInterface
+		accuracy   accuracy.AccuracyInterface
+		optimizer  optimizer.OptimizerInterface
+	}{
+		{&activation.LinearActivation{}, &loss.MeanSquaredErrorLoss{}, &accuracy.RegressionAccuracy{Precision: 0.25}, &ada},
+		{&activation.LinearActivation{}, &loss.MeanAbsoluteErrorLoss{}, &accuracy.RegressionAccuracy{Precision: 0.5}, &rmsprop},
+		{&activation.SigmoidActivation{}, &loss.BinaryCrossentropyLoss{}, &accuracy.BinaryCategorialAccuracy{}, &sgd},
+		{&activation.SoftmaxActivation{}, &loss.CategoricalCrossentropyLoss{}, &accuracy.CategorialAccuracy{}, &adam},
+	}
+
+	for i, c := range cases {
+		m := model.Model{Name: "test"}
+		m.Add((&layer.DenseLayer{}).Initialization(2, 4))
+		m.Add(&activation.Activation_ReLU{})
+		m.Add((&layer.DenseLayer{}).Initialization(4, 2))
+		m.Add(c.activation)
+		m.Set(c.loss, c.optimizer, c.accuracy)
+		m.Finalize()
+
+		loaded := storeAndLoad(t, &m)
+
+		if reflect.TypeOf(loaded.Layers[3]) != reflect.TypeOf(c.activation) {
+			t.Errorf("case %v: unexpected activation: %v", i, reflect.TypeOf(loaded.Layers[3]))
+		}
+		if reflect.TypeOf(loaded.Loss) != reflect.TypeOf(c.loss) {
+			t.Errorf("case %v: unexpected loss: %v", i, reflect.TypeOf(loaded.Loss))
+		}
+		if !reflect.DeepEqual(loaded.Accuracy, c.accuracy) {
+			t.Errorf("case %v: unexpected accuracy: %v", i, loaded.Accuracy)
+		}
+		if !reflect.DeepEqual(loaded.Optimizer, c.optimizer) {
+			t.Errorf("case %v: unexpected optimizer: %v", i, loaded.Optimizer)
+		}
+	}
+}
+
+func TestJSONModelDataProviderOptimizedSoftmax(t *testing.T) {
+	m := model.Model{}
+	m.Add((&layer.DenseLayer{}).Initialization(2, 3))
+	a, l := optimizations.MakeOptimizedCategorialCrossentropy()
+	m.Add(&a)
+	o := optimizer.NewAdam()
+	m.Set(&l, &o, &accuracy.CategorialAccuracy{})
+	m.Finalize()
+
+	loaded := storeAndLoad(t, &m)
+
+	x := mat.NewDense(2, 2, []float64{0.1, 0.2, 0.3, 0.4})
+	y := mat.NewDense(2, 1, []float64{0, 2})
+	output := loaded.Forward(*x, true)
+	// would panic if activation and loss were not linked
+	loaded.Backward(*output, *y)
+}
+
+func TestJSONModelDataProviderLegacyModel(t *testing.T) {
+	// models stored with type names of Go types
+	for _, path := range []string{"../assets/fashion-dense.json", "../assets/fashion-cnn-max-pooling.json"} {
+		provider := model.JSONModelDataProvider{}
+		m, err := provider.Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(m.Layers) < 6 {
+			t.Fatalf("%v: unexpected number of layers: %v", path, len(m.Layers))
+		}
+		if _, ok := m.Accuracy.(*accuracy.CategorialAccuracy); !ok {
+			t.Fatalf("%v: unexpected accuracy: %v", path, reflect.TypeOf(m.Accuracy))
+		}
+	}
+}
+
+func TestJSONModelDataProviderCorruptedLayer(t *testing.T) {
+	for _, name := range []string{"dense", "convolution", "max_pooling", "batch_norm"} {
+		path := filepath.Join(t.TempDir(), "model.json")
+		document := `{"name": "", "layers": [{"type": "` + name + `", "data": {"weights": 1, "kernels": 1, "pool_size": "1", "channels": "1"}}]}`
+		if err := os.WriteFile(path, []byte(document), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		provider := model.JSONModelDataProvider{}
+		if _, err := provider.Load(path); err == nil {
+			t.Fatalf("%v: missing error for corrupted layer", name)
+		}
+	}
+}
+
+func storeAndLoad(t *testing.T, m *model.Model) *model.Model {
+	path := filepath.Join(t.TempDir(), "model.json")
+	provider := model.JSONModelDataProvider{}
+
+	err := provider.Store(path, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := provider.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loaded
+}
+
+func TestModelDataProviderStoresScaler(t *testing.T) {
+	m := model.Model{}
+	m.Add((&layer.DenseLayer{}).Initialization(2, 1))
+	m.Add(&activation.LinearActivation{})
+	o := optimizer.NewAdam()
+	m.Set(&loss.MeanSquaredErrorLoss{}, &o, &accuracy.RegressionAccuracy{})
+
+	x := mat.NewDense(3, 2, []float64{100, 0.1, 200, 0.2, 400, 0.3})
+	robust := scaler.RobustScaler{}
+	robust.Fit(x)
+	m.Scaler = &robust
+	m.Finalize()
+	expected := m.Predict(x, nil)
+
+	for _, name := range []string{"model.json", "model.bin"} {
+		path := filepath.Join(t.TempDir(), name)
+		provider := model.ProviderForPath(path)
+		if err := provider.Store(path, &m); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := provider.Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(loaded.Scaler, m.Scaler) {
+			t.Fatalf("%v: unexpected scaler: %v", name, loaded.Scaler)
+		}
+		predictions := loaded.Predict(x, nil)
+		if !mat.Equal(&expected, &predictions) {
+			t.Fatalf("%v: predictions of loaded model differ", name)
+		}
+	}
+}
+
+func TestJSONModelDataProviderOptimizers(t *testing.T) {
+	adamW := optimizer.NewAdamW()
+	adamW.WeightDecay = 0.05
+	nadam := optimizer.NewNadam()
+	nadam.Beta1 = 0.95
+	adaMax := optimizer.NewAdaMax()
+	adaMax.Iterations = 7
+	adadelta := optimizer.NewAdadelta()
+	adadelta.Rho = 0.95
+	nesterov := optimizer.NewSGD(0.1, 1e-3, 0.9)
+	nesterov.Nesterov = true
+
+	for _, o := range []optimizer.OptimizerInterface{&adamW, &nadam, &adaMax, &adadelta, &nesterov} {
+		m := model.Model{}
+		m.Add((&layer.DenseLayer{}).Initialization(2, 1))
+		m.Add(&activation.LinearActivation{})
+		m.Set(&loss.MeanSquaredErrorLoss{}, o, &accuracy.RegressionAccuracy{})
+		m.Finalize()
+
+		loaded := storeAndLoad(t, &m)
+		if !reflect.DeepEqual(loaded.Optimizer, o) {
+			t.Errorf("%v: unexpected loaded optimizer: %#v", o.Name(), loaded.Optimizer)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"main/model/marshaling"
+	"reflect"
+	"sort"
+)
+
+// Registry maps stable type names used in stored files to functions which encode and decode values of the type
+// types from other packages can be registered to be stored by JSONModelDataProvider, e.g. in init():
+//
+//	model.LayerRegistry.Register("my_layer", func() layer.LayerInterface { return &MyLayer{} }, encode, decode)
+type Registry[T any] struct {
+	kind    string
+	entries map[string]*registryEntry[T]
+	// stable name of every registered Go type
+	names map[reflect.Type]string
+}
+
+// encodes value into data stored in the file
+// matrices wrapped with tensors.Wrap are stored as raw data by binary files; tensors is nil for JSON files
+type EncodeFunc[T any] func(value T, tensors *marshaling.TensorTable) ([]byte, error)
+
+// creates value from data stored in the file; tensors resolve matrices stored with EncodeFunc
+type DecodeFunc[T any] func(data []byte, tensors *marshaling.TensorTable) (T, error)
+
+type registryEntry[T any] struct {
+	name   string
+	encode EncodeFunc[T]
+	decode DecodeFunc[T]
+}
+
+func NewRegistry[T any](kind string) *Registry[T] {
+	return &Registry[T]{
+		kind:    kind,
+		entries: map[string]*registryEntry[T]{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// Register adds type of values created by factory under name
+// nil encode means the type has no data to store; nil decode creates value with factory
+// aliases are accepted on load only, e.g. names used by older versions of the file format
+func (registry *Registry[T]) Register(name string, factory func() T, encode EncodeFunc[T], decode DecodeFunc[T], aliases ...string) {
+	if encode == nil {
+		encode = func(value T, tensors *marshaling.TensorTable) ([]byte, error) { return nil, nil }
+	}
+	if decode == nil {
+		decode = func(data []byte, tensors *marshaling.TensorTable) (T, error) { return factory(), nil }
+	}
+
+	entry := &registryEntry[T]{name: name, encode: encode, decode: decode}
+	for _, key := range append([]string{name}, aliases...) {
+		if _, ok := registry.entries[key]; ok {
+			log.Fatalf("%v type %v is already registered", registry.kind, key)
+		}
+		registry.entries[key] = entry
+	}
+
+	valueType := reflect.TypeOf(factory())
+	if _, ok := registry.names[valueType]; ok {
+		log.Fatalf("%v type %v is already registered", registry.kind, valueType)
+	}
+	registry.names[valueType] = name
+}
+
+// RegisterJSON adds type which is stored as its own JSON representation
+func (registry *Registry[T]) RegisterJSON(name string, factory func() T, aliases ...string) {
+	encode := func(value T, tensors *marshaling.TensorTable) ([]byte, error) {
+		return json.Marshal(value)
+	}
+	decode := func(data []byte, tensors *marshaling.TensorTable) (T, error) {
+		value := factory()
+		if len(data) == 0 {
+			return value, nil
+		}
+		err := json.Unmarshal(data, value)
+		return value, err
+	}
+	registry.Register(name, factory, encode, decode, aliases...)
+}
+
+// Names returns sorted stable names of registered types
+func (registry *Registry[T]) Names() []string {
+	names := make([]string, 0, len(registry.names))
+	for _, name := range registry.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// wraps value with its stable type name
+func (registry *Registry[T]) encode(value T, tensors *marshaling.TensorTable) (typedValue, error) {
+	name, ok := registry.names[reflect.TypeOf(value)]
+	if !ok {
+		return typedValue{}, fmt.Errorf("unregistered %v type: %v", registry.kind, reflect.TypeOf(value))
+	}
+
+	data, err := registry.entries[name].encode(value, tensors)
+	if err != nil {
+		return typedValue{}, err
+	}
+	return typedValue{Type: name, Data: data}, nil
+}
+
+// creates value of registered type and loads its data
+func (registry *Registry[T]) decode(value typedValue, tensors *marshaling.TensorTable) (T, error) {
+	entry, ok := registry.entries[value.Type]
+	if !ok {
+		var empty T
+		return empty, fmt.Errorf("unregistered %v type: %v", registry.kind, value.Type)
+	}
+	return entry.decode(value.Data, tensors)
+}
+
+// typedValue is stored representation of a registered value
+type typedValue struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+func (value *typedValue) UnmarshalJSON(data []byte) error {
+	// loss and accuracy of older models are stored as type name only
+	name := ""
+	if err := json.Unmarshal(data, &name); err == nil {
+		value.Type = name
+		return nil
+	}
+
+	wrap := struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}{}
+	err := json.Unmarshal(data, &wrap)
+	if err != nil {
+		return err
+	}
+	value.Type = wrap.Type
+	value.Data = wrap.Data
+	return nil
+}
@@ -0,0 +1,113 @@
+package model_test
+
+import (
+	"encoding/json"
+	"main/accuracy"
+	"main/layer"
+	"main/loss"
+	"main/model"
+	"main/model/marshaling"
+	"main/optimizer"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// layer defined outside of model package
+type scaleLayer struct {
+	Factor  float64
+	Output  mat.Dense
+	DInputs mat.Dense
+}
+
+func (l *scaleLayer) Name() string { return "Scale Layer" }
+
+func (l *scaleLayer) Forward(inputs *mat.Dense, isTraining bool) {
+	l.Output.Scale(l.Factor, inputs)
+}
+
+func (l *scaleLayer) Backward(dvalues *mat.Dense) {
+	l.DInputs.Scale(l.Factor, dvalues)
+}
+
+func (l *scaleLayer) GetOutput() *mat.Dense  { return &l.Output }
+func (l *scaleLayer) GetDInputs() *mat.Dense { return &l.DInputs }
+
+func init() {
+	model.LayerRegistry.Register("test_scale", func() layer.LayerInterface { return &scaleLayer{} },
+		func(value layer.LayerInterface, tensors *marshaling.TensorTable) ([]byte, error) {
+			return json.Marshal(value.(*scaleLayer).Factor)
+		},
+		func(data []byte, tensors *marshaling.TensorTable) (layer.LayerInterface, error) {
+			l := scaleLayer{}
+			err := json.Unmarshal(data, &l.Factor)
+			return &l, err
+		})
+}
+
+func TestRegistryCustomLayer(t *testing.T) {
+	m := model.Model{}
+	m.Add((&layer.DenseLayer{}).Initialization(2, 2))
+	m.Add(&scaleLayer{Factor: 3})
+	o := optimizer.NewSGD(1.0, 0.0, 0.0)
+	m.Set(&loss.MeanSquaredErrorLoss{}, &o, &accuracy.RegressionAccuracy{})
+	m.Finalize()
+
+	path := filepath.Join(t.TempDir(), "model.json")
+	provider := model.JSONModelDataProvider{}
+	err := provider.Store(path, &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{`"type":"dense"`, `"type":"test_scale"`, `"type":"mean_squared_error"`, `"type":"sgd"`} {
+		if !strings.Contains(string(data), name) {
+			t.Errorf("Stored model doesn't contain %v", name)
+		}
+	}
+
+	loaded, err := provider.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scale, ok := loaded.Layers[1].(*scaleLayer)
+	if !ok || scale.Factor != 3 {
+		t.Fatalf("Unexpected layer: %v", loaded.Layers[1])
+	}
+}
+
+func TestRegistryUnregisteredType(t *testing.T) {
+	type unknownLayer struct{ scaleLayer }
+
+	m := model.Model{}
+	m.Add(&unknownLayer{})
+	o := optimizer.NewSGD(1.0, 0.0, 0.0)
+	m.Set(&loss.MeanSquaredErrorLoss{}, &o, &accuracy.RegressionAccuracy{})
+
+	provider := model.JSONModelDataProvider{}
+	err := provider.Store(filepath.Join(t.TempDir(), "model.json"), &m)
+	if err == nil {
+		t.Fatal("Expected error for unregistered layer")
+	}
+}
+
+func TestRegistryNames(t *testing.T) {
+	names := model.OptimizerRegistry.Names()
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("Names are not sorted: %v", names)
+	}
+	for _, name := range []string{"adagrad", "adam", "rmsprop", "sgd"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("%v is not registered", name)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import "fmt"
+
+// Reporter receives progress of Model.Train and Model.Evaluate
+// Model doesn't print anything without a reporter
+type Reporter interface {
+	TrainBegin(m *Model)
+	EpochBegin(m *Model, epoch int)
+	// called for every printEvery step of Train and for the last step of every epoch
+	StepEnd(m *Model, record StepRecord)
+	EpochEnd(m *Model, record EpochRecord)
+	Evaluation(m *Model, result Evaluation)
+}
+
+// PrintReporter prints progress to stdout
+type PrintReporter struct{}
+
+func (r PrintReporter) TrainBegin(m *Model) {
+	fmt.Println("================================")
+	fmt.Println(m.Name, "Training")
+}
+
+func (r PrintReporter) EpochBegin(m *Model, epoch int) {
+	fmt.Println(m.Name, "Epoch", epoch)
+}
+
+func (r PrintReporter) StepEnd(m *Model, record StepRecord) {
+	fmt.Println(m.Name, "step:", record.Step, "\n",
+		"loss:", record.Loss,
+		"(data loss:", record.DataLoss, "reg loss:", record.RegularizationLoss, ") ",
+		"acc:", record.Accuracy,
+		"lr", record.LearningRate)
+}
+
+func (r PrintReporter) EpochEnd(m *Model, record EpochRecord) {
+	fmt.Println(m.Name, "training, ",
+		"loss:", record.Loss,
+		"(data_loss:", record.DataLoss, "reg_loss:", record.RegularizationLoss, ") ",
+		"acc:", record.Accuracy,
+		"lr", record.LearningRate)
+}
+
+func (r PrintReporter) Evaluation(m *Model, result Evaluation) {
+	fmt.Println(m.Name, "validation:", "loss:", result.Loss, "accuracy:", result.Accuracy)
+}
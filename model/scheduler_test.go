@@ -0,0 +1,94 @@
+package model_test
+
+import (
+	"main/model"
+	"main/optimizer"
+	"main/scheduler"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReduceOnPlateauFollowsEpochLoss(t *testing.T) {
+	m := newCheckpointTestModel()
+	plateau := scheduler.NewReduceOnPlateau(0.5, 0)
+	// no epoch is good enough to be an improvement
+	plateau.MinDelta = 1e9
+	m.Optimizer.(optimizer.ScheduledOptimizer).SetScheduler(&plateau)
+
+	history := m.Train(callbackTestData(), 3, nil, 0, nil)
+
+	expected := []float64{0.05, 0.05, 0.025, 0.0125}
+	for i, record := range history.Epochs {
+		if math.Abs(record.LearningRate-expected[i]) > 1e-12 {
+			t.Fatalf("epoch %v: expected learning rate %v, got %v", i, expected[i], record.LearningRate)
+		}
+	}
+}
+
+// metric scheduler counts only epochs with validation
+func TestReduceOnPlateauFollowsValidationLoss(t *testing.T) {
+	m := newCheckpointTestModel()
+	plateau := scheduler.NewReduceOnPlateau(0.5, 0)
+	plateau.MinDelta = 1e9
+	m.Optimizer.(optimizer.ScheduledOptimizer).SetScheduler(&plateau)
+
+	validation := callbackTestData()
+	history := m.Train(callbackTestData(), 3, nil, 0, &validation, model.ValidateEvery(2))
+
+	// validation after epochs 1 and 3; the second one reduces learning rate after the last epoch
+	for i, record := range history.Epochs {
+		if math.Abs(record.LearningRate-0.05) > 1e-12 {
+			t.Fatalf("epoch %v: expected learning rate 0.05, got %v", i, record.LearningRate)
+		}
+	}
+	if plateau.Scale != 0.5 {
+		t.Fatalf("expected one reduction, got scale %v", plateau.Scale)
+	}
+}
+
+func TestSchedulerIsStored(t *testing.T) {
+	m := newCheckpointTestModel()
+	plateau := scheduler.NewReduceOnPlateau(0.5, 1)
+	warmup := scheduler.LinearWarmup{WarmupSteps: 3, After: &plateau}
+	m.Optimizer.(optimizer.ScheduledOptimizer).SetScheduler(&warmup)
+	m.Train(callbackTestData(), 5, nil, 0, nil)
+
+	loaded := storeAndLoad(t, m)
+
+	stored := loaded.Optimizer.(optimizer.ScheduledOptimizer).GetScheduler()
+	if !reflect.DeepEqual(stored, &warmup) {
+		t.Fatalf("unexpected scheduler: %#v", stored)
+	}
+	m.Optimizer.PreUpdate()
+	loaded.Optimizer.PreUpdate()
+	if m.Optimizer.GetCurrentLearningRate() != loaded.Optimizer.GetCurrentLearningRate() {
+		t.Fatal("loaded model has to continue the schedule")
+	}
+}
+
+func TestInvalidStoredSchedulerIsRejected(t *testing.T) {
+	m := newCheckpointTestModel()
+	m.Optimizer.(optimizer.ScheduledOptimizer).SetScheduler(&scheduler.CosineWarmRestarts{Period: 10})
+	path := filepath.Join(t.TempDir(), "model.json")
+	provider := model.JSONModelDataProvider{}
+	if err := provider.Store(path, m); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// file without period
+	data = []byte(strings.Replace(string(data), `"period":10`, `"period":0`, 1))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Load(path); err == nil {
+		t.Fatal("missing error for scheduler without period")
+	}
+}
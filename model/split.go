@@ -0,0 +1,153 @@
+package model
+
+import (
+	"log"
+	"slices"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Splitter divides samples into training, validation and test data
+type Splitter struct {
+	// fractions of samples used for validation and test; the rest is used for training
+	Validation float64
+	Test       float64
+
+	// keeps proportions of classes in every part; classes are taken from Y:
+	// its only column with class indexes or the largest column of one-hot rows
+	Stratify bool
+	Seed     uint64
+}
+
+// Split returns randomly selected parts of data; samples of every part keep their original order
+func (splitter Splitter) Split(data ModelData) (training, validation, test ModelData) {
+	random := rand.New(rand.NewSource(splitter.Seed))
+
+	var trainingIndexes, validationIndexes, testIndexes []int
+	for _, group := range groupSamples(data, splitter.Stratify) {
+		random.Shuffle(len(group), func(i, j int) {
+			group[i], group[j] = group[j], group[i]
+		})
+		testCount := fractionOf(len(group), splitter.Test)
+		validationCount := min(fractionOf(len(group), splitter.Validation), len(group)-testCount)
+
+		testIndexes = append(testIndexes, group[:testCount]...)
+		validationIndexes = append(validationIndexes, group[testCount:testCount+validationCount]...)
+		trainingIndexes = append(trainingIndexes, group[testCount+validationCount:]...)
+	}
+
+	return selectSamples(data, trainingIndexes), selectSamples(data, validationIndexes), selectSamples(data, testIndexes)
+}
+
+// KFold divides samples into K folds; every fold is used for validation once while the others are used for training
+type KFold struct {
+	K int
+
+	// keeps proportions of classes in every fold, see Splitter.Stratify
+	Stratify bool
+	// samples are shuffled before splitting; otherwise folds are contiguous ranges (of every class)
+	Shuffle bool
+	Seed    uint64
+}
+
+// Fold is training and validation data of one fold
+type Fold struct {
+	Training   ModelData
+	Validation ModelData
+}
+
+// every fold needs training and validation samples, so K has to be between 2 and the number of samples
+func (kfold KFold) Folds(data ModelData) []Fold {
+	samples, _ := data.X.Dims()
+	if kfold.K < 2 || kfold.K > samples {
+		log.Fatalf("Invalid number of folds %v for %v samples", kfold.K, samples)
+	}
+
+	random := rand.New(rand.NewSource(kfold.Seed))
+
+	folds := make([][]int, kfold.K)
+	for _, group := range groupSamples(data, kfold.Stratify) {
+		if kfold.Shuffle {
+			random.Shuffle(len(group), func(i, j int) {
+				group[i], group[j] = group[j], group[i]
+			})
+		}
+		// the first len(group) % K folds get one more sample
+		from := 0
+		for i := range folds {
+			count := len(group) / kfold.K
+			if i < len(group)%kfold.K {
+				count += 1
+			}
+			folds[i] = append(folds[i], group[from:from+count]...)
+			from += count
+		}
+	}
+
+	result := make([]Fold, kfold.K)
+	for i := range folds {
+		training := []int{}
+		for j, fold := range folds {
+			if j != i {
+				training = append(training, fold...)
+			}
+		}
+		result[i] = Fold{Training: selectSamples(data, training), Validation: selectSamples(data, folds[i])}
+	}
+	return result
+}
+
+// returns indexes of samples of every class, or of all samples without stratification
+func groupSamples(data ModelData, stratify bool) [][]int {
+	samples, _ := data.X.Dims()
+	if !stratify {
+		return [][]int{makeIndexes(samples)}
+	}
+
+	groups := map[int][]int{}
+	classes := []int{}
+	for i := 0; i < samples; i++ {
+		class := sampleClass(&data.Y, i)
+		if _, ok := groups[class]; !ok {
+			classes = append(classes, class)
+		}
+		groups[class] = append(groups[class], i)
+	}
+
+	slices.Sort(classes)
+	result := make([][]int, 0, len(classes))
+	for _, class := range classes {
+		result = append(result, groups[class])
+	}
+	return result
+}
+
+func sampleClass(y *mat.Dense, i int) int {
+	row := y.RawRowView(i)
+	if len(row) == 1 {
+		return int(row[0])
+	}
+	return floats.MaxIdx(row)
+}
+
+func makeIndexes(count int) []int {
+	indexes := make([]int, count)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+func fractionOf(count int, fraction float64) int {
+	return int(float64(count)*fraction + 0.5)
+}
+
+func selectSamples(data ModelData, indexes []int) ModelData {
+	if len(indexes) == 0 {
+		return ModelData{}
+	}
+	slices.Sort(indexes)
+	return ModelData{X: gatherRows(&data.X, indexes), Y: gatherRows(&data.Y, indexes)}
+}
@@ -0,0 +1,112 @@
+package model_test
+
+import (
+	"main/model"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// sample i has feature i; the first 30 samples are class 0, the last 10 are class 1
+func splitTestData() model.ModelData {
+	x := mat.NewDense(40, 1, nil)
+	y := mat.NewDense(40, 1, nil)
+	for i := 0; i < 40; i++ {
+		x.Set(i, 0, float64(i))
+		if i >= 30 {
+			y.Set(i, 0, 1)
+		}
+	}
+	return model.ModelData{X: *x, Y: *y}
+}
+
+func countClass(data model.ModelData, class float64) int {
+	count := 0
+	for _, v := range mat.Col(nil, 0, &data.Y) {
+		if v == class {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSplitterStratify(t *testing.T) {
+	data := splitTestData()
+	splitter := model.Splitter{Validation: 0.2, Test: 0.1, Stratify: true, Seed: 3}
+	training, validation, test := splitter.Split(data)
+
+	cases := []struct {
+		data           model.ModelData
+		class0, class1 int
+	}{
+		{training, 21, 7},
+		{validation, 6, 2},
+		{test, 3, 1},
+	}
+	seen := map[float64]bool{}
+	for i, c := range cases {
+		if countClass(c.data, 0) != c.class0 || countClass(c.data, 1) != c.class1 {
+			t.Errorf("part %v: unexpected classes: %v and %v", i, countClass(c.data, 0), countClass(c.data, 1))
+		}
+		for _, v := range mat.Col(nil, 0, &c.data.X) {
+			if seen[v] {
+				t.Fatalf("sample %v is in several parts", v)
+			}
+			seen[v] = true
+		}
+	}
+	if len(seen) != 40 {
+		t.Fatalf("only %v samples are split", len(seen))
+	}
+}
+
+func TestKFold(t *testing.T) {
+	data := splitTestData()
+	folds := model.KFold{K: 3, Stratify: true, Shuffle: true, Seed: 5}.Folds(data)
+
+	if len(folds) != 3 {
+		t.Fatalf("unexpected number of folds: %v", len(folds))
+	}
+	validated := map[float64]int{}
+	for i, fold := range folds {
+		trainingRows, _ := fold.Training.X.Dims()
+		validationRows, _ := fold.Validation.X.Dims()
+		if trainingRows+validationRows != 40 {
+			t.Errorf("fold %v: unexpected number of samples: %v", i, trainingRows+validationRows)
+		}
+		if countClass(fold.Validation, 0) != 10 || countClass(fold.Validation, 1) < 3 {
+			t.Errorf("fold %v: classes are not stratified", i)
+		}
+		for _, v := range mat.Col(nil, 0, &fold.Validation.X) {
+			validated[v]++
+		}
+	}
+	for i := 0; i < 40; i++ {
+		if validated[float64(i)] != 1 {
+			t.Fatalf("sample %v is validated %v times", i, validated[float64(i)])
+		}
+	}
+}
+
+func TestCrossValidate(t *testing.T) {
+	created := 0
+	factory := func() *model.Model {
+		created++
+		return newCheckpointTestModel()
+	}
+
+	result := model.CrossValidate(factory, callbackTestData(), model.KFold{K: 4}, 3, nil, 0)
+
+	if created != 4 || len(result.Folds) != 4 || len(result.Histories) != 4 {
+		t.Fatalf("expected 4 trained models, got %v", created)
+	}
+	// the same summation as CrossValidate, so the mean is exactly equal
+	losses := []float64{}
+	for _, evaluation := range result.Folds {
+		losses = append(losses, evaluation.Loss)
+	}
+	if mean := stat.Mean(losses, nil); mean != result.Mean.Loss || result.Std.Loss <= 0 {
+		t.Fatalf("unexpected statistics: %+v %+v", result.Mean, result.Std)
+	}
+}
@@ -32,5 +32,6 @@ func RunBinaryModel() {
 	m.Set(&l, &o, &a)
 
 	m.Finalize()
+	m.Reporter = model.PrintReporter{}
 	m.Train(model.ModelData{X: x, Y: y}, 10000, nil, 100, &model.ModelData{X: x_val, Y: y_val})
 }
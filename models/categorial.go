@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"main/accuracy"
 	"main/activation"
 	"main/dataset"
@@ -14,6 +15,25 @@ func RunCategorialModel() {
 	x, y := dataset.SpiralData(1000, 3)
 	x_val, y_val := dataset.SpiralData(1000, 3)
 
+	m := createCategorialModel()
+	m.Reporter = model.PrintReporter{}
+	m.Train(model.ModelData{X: x, Y: y}, 10000, nil, 100, &model.ModelData{X: x_val, Y: y_val})
+}
+
+// trains a new model on every of 5 folds of spiral data and prints mean and standard deviation of validation metrics
+func CrossValidateCategorialModel() {
+	x, y := dataset.SpiralData(1000, 3)
+
+	kfold := model.KFold{K: 5, Stratify: true, Shuffle: true, Seed: 1}
+	result := model.CrossValidate(createCategorialModel, model.ModelData{X: x, Y: y}, kfold, 1000, nil, 0)
+
+	for i, evaluation := range result.Folds {
+		fmt.Printf("fold: %v, acc: %.3f, loss: %.3f\n", i, evaluation.Accuracy, evaluation.Loss)
+	}
+	fmt.Printf("acc: %.3f ± %.3f, loss: %.3f ± %.3f\n", result.Mean.Accuracy, result.Std.Accuracy, result.Mean.Loss, result.Std.Loss)
+}
+
+func createCategorialModel() *model.Model {
 	m := model.Model{}
 
 	layer1 := layer.DenseLayer{}
@@ -38,5 +58,5 @@ func RunCategorialModel() {
 	m.Set(&l, &o, &a)
 
 	m.Finalize()
-	m.Train(model.ModelData{X: x, Y: y}, 10000, nil, 100, &model.ModelData{X: x_val, Y: y_val})
+	return &m
 }
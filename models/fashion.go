@@ -6,6 +6,7 @@ import (
 	"log"
 	"main/accuracy"
 	"main/activation"
+	"main/augmentation"
 	"main/dataset"
 	"main/layer"
 	"main/loss"
@@ -91,6 +92,45 @@ func createCNNWithMaxPoolingLayerModel() *model.Model {
 	return m
 }
 
+// the same as createCNNWithMaxPoolingLayerModel, but training images are randomly changed to reduce overfitting
+func createCNNAugmentedModel() *model.Model {
+	m := &model.Model{}
+	m.Name = "CNN - Augmented"
+
+	inputImageShape := layer.InputShape{Depths: 1, Height: 28, Width: 28}
+	// images are stored transposed (see utils.NormalizeGrascaleImageData), so flipping rows mirrors them horizontally
+	// -1 is black background of normalized images
+	m.Add((&augmentation.AugmentationLayer{}).Initialization(inputImageShape,
+		&augmentation.VerticalFlip{Probability: 0.5},
+		&augmentation.Shift{MaxShift: 2, Fill: -1},
+		&augmentation.Rotation{MaxDegrees: 10, Fill: -1},
+		&augmentation.Brightness{MaxDelta: 0.1},
+		&augmentation.Noise{StdDev: 0.05},
+	))
+
+	cnnLayer := (&layer.ConvolutionLayer{}).Initialization(inputImageShape, 3, 5)
+	m.Add(cnnLayer)
+	m.Add(&activation.SigmoidActivation{})
+	maxPooling := (&layer.MaxPoolingLayer{}).Initialization(cnnLayer.OutputShape, 2)
+	m.Add(maxPooling)
+
+	m.Add((&layer.DenseLayer{}).Initialization(maxPooling.OutputShape.TotalSize(), 128))
+	m.Add(&activation.Activation_ReLU{})
+
+	m.Add((&layer.DenseLayer{}).Initialization(128, 128))
+	m.Add(&activation.Activation_ReLU{})
+
+	m.Add((&layer.DenseLayer{}).Initialization(128, 10))
+	m.Add(&activation.SoftmaxActivation{})
+
+	o := optimizer.NewAdam()
+	o.Decay = 1e-5
+
+	m.Set(&loss.CategoricalCrossentropyLoss{}, &o, &accuracy.CategorialAccuracy{})
+	m.Finalize()
+	return m
+}
+
 func createCNNTwoLayerModel() *model.Model {
 	m := &model.Model{}
 	m.Name = "CNN - 2"
@@ -138,26 +178,27 @@ func createCNNBigModel() *model.Model {
 	m := &model.Model{}
 	m.Name = "CNN - Big"
 
+	// Conv2D of Keras uses stride 1 and valid padding by default
+	options := layer.ConvolutionOptions{Stride: 1, Padding: layer.PaddingValid}
+
 	inputImageShape := layer.InputShape{Depths: 1, Height: 28, Width: 28}
-	cnnLayer1 := (&layer.ConvolutionLayer{}).Initialization(inputImageShape, 32, 3)
+	cnnLayer1 := (&layer.ConvolutionLayer{}).InitializationWithOptions(inputImageShape, 32, 3, options)
 	m.Add(cnnLayer1)
 	m.Add(&activation.Activation_ReLU{})
 	maxPooling1 := (&layer.MaxPoolingLayer{}).Initialization(cnnLayer1.OutputShape, 2)
 	m.Add(maxPooling1)
 
-	cnnLayer2 := (&layer.ConvolutionLayer{}).Initialization(maxPooling1.OutputShape, 64, 3)
+	cnnLayer2 := (&layer.ConvolutionLayer{}).InitializationWithOptions(maxPooling1.OutputShape, 64, 3, options)
 	m.Add(cnnLayer2)
 	m.Add(&activation.Activation_ReLU{})
 	maxPooling2 := (&layer.MaxPoolingLayer{}).Initialization(cnnLayer2.OutputShape, 2)
 	m.Add(maxPooling2)
 
-	// cnnLayer3 := (&layer.ConvolutionLayer{}).Initialization(maxPooling2.OutputShape, 64, 3)
-	// m.Add(cnnLayer3)
-	// m.Add(&activation.Activation_ReLU{})
-	// maxPooling3 := (&layer.MaxPoolingLayer{}).Initialization(cnnLayer3.OutputShape, 2)
-	// m.Add(maxPooling3)
+	cnnLayer3 := (&layer.ConvolutionLayer{}).InitializationWithOptions(maxPooling2.OutputShape, 64, 3, options)
+	m.Add(cnnLayer3)
+	m.Add(&activation.Activation_ReLU{})
 
-	m.Add((&layer.DenseLayer{}).Initialization(maxPooling2.OutputShape.TotalSize(), 250))
+	m.Add((&layer.DenseLayer{}).Initialization(cnnLayer3.OutputShape.TotalSize(), 250))
 	m.Add(&activation.Activation_ReLU{})
 
 	m.Add((&layer.DenseLayer{}).Initialization(250, 125))
@@ -200,8 +241,9 @@ func trainModeAndStore(m *model.Model, path string, epochs int) error {
 	validationData := model.ModelData{X: *x_val, Y: *y_val}
 
 	batchSize := 128
-	m.Train(trainingData, epochs, &batchSize, 100, &validationData)
-	m.Evaluate(validationData, &batchSize)
+	m.Reporter = model.PrintReporter{}
+	// Train evaluates validation data after the last epoch and reports it
+	m.Train(trainingData, epochs, &batchSize, 100, &validationData, model.ValidateEvery(1), model.Shuffle(1))
 
 	dataProvider := model.JSONModelDataProvider{}
 	err = dataProvider.Store(path, m)
@@ -225,8 +267,9 @@ func TrainModels() {
 	trainModeAndStore(createDenseModel(numInputs), "./assets/fashion-dense.json", 10)
 	trainModeAndStore(createCNNOneLayerModel(), "./assets/fashion-cnn-1.json", 30)
 	trainModeAndStore(createCNNWithMaxPoolingLayerModel(), "./assets/fashion-cnn-max-pooling.json", 30)
+	trainModeAndStore(createCNNAugmentedModel(), "./assets/fashion-cnn-augmented.json", 30)
 	trainModeAndStore(createCNNTwoLayerModel(), "./assets/fashion-cnn-2.json", 30)
-	// trainModeAndStore(createCNNBigModel(), "./assets/fashion-cnn-big.json", 20)
+	trainModeAndStore(createCNNBigModel(), "./assets/fashion-cnn-big.json", 20)
 
 	fmt.Println("training is done")
 }
@@ -266,6 +309,7 @@ func LoadModels() {
 		}
 
 		fmt.Println(m.Name)
+		m.Reporter = model.PrintReporter{}
 		m.Evaluate(validationData, &batchSize)
 	}
 }
@@ -34,6 +34,7 @@ func RunRegressionModel() {
 	m.Description()
 
 	m.Finalize()
+	m.Reporter = model.PrintReporter{}
 
 	m.Train(model.ModelData{X: x, Y: y}, 10000, nil, 100, nil)
 }
@@ -26,16 +26,32 @@ func Correlate2dOps(input mat.Matrix, kernel mat.Matrix) (float64, error) {
 // input - 2D array of image (currentl grayscale?)
 // kernel - 2D array
 func Correlate2dValid(input mat.Dense, kernel mat.Dense) (mat.Dense, error) {
-	// allocate output based on formula M-N+1
-	// calculate the output
-	n, _ := input.Dims()
-	m, _ := kernel.Dims()
-	s := n - m + 1
-	output := mat.NewDense(s, s, nil)
-
-	for i := 0; i < s; i++ {
-		for j := 0; j < s; j++ {
-			value, err := Correlate2dOps(input.Slice(i, i+m, j, j+m), &kernel)
+	return Correlate2d(input, kernel, 1, 0)
+}
+
+// cross-correlation of input and kernel with given stride and zero padding
+// output size is calculated by formula (N + 2P - M) / S + 1
+func Correlate2d(input mat.Dense, kernel mat.Dense, stride int, padding int) (mat.Dense, error) {
+	m, n := kernel.Dims()
+	if stride < 1 {
+		return *mat.NewDense(1, 1, nil), errors.New("stride has to be positive")
+	}
+
+	paddedInput := Pad2d(input, padding)
+	rows, cols := paddedInput.Dims()
+	if rows < m || cols < n {
+		return *mat.NewDense(1, 1, nil), errors.New("kernel is bigger than input")
+	}
+
+	outputRows := (rows-m)/stride + 1
+	outputCols := (cols-n)/stride + 1
+	output := mat.NewDense(outputRows, outputCols, nil)
+
+	for i := 0; i < outputRows; i++ {
+		for j := 0; j < outputCols; j++ {
+			startI := i * stride
+			startJ := j * stride
+			value, err := Correlate2dOps(paddedInput.Slice(startI, startI+m, startJ, startJ+n), &kernel)
 			if err != nil {
 				return *mat.NewDense(1, 1, nil), err
 			}
@@ -46,31 +62,59 @@ func Correlate2dValid(input mat.Dense, kernel mat.Dense) (mat.Dense, error) {
 	return *output, nil
 }
 
+// full cross-correlation: input is padded by kernelSize - 1 so every overlap between input and kernel is used
 func Correlate2DFull(input mat.Dense, kernel mat.Dense) (mat.Dense, error) {
-	inputSize, _ := input.Dims()
-	kernelSize, _ := kernel.Dims()
-	s := inputSize + 2 - kernelSize + 1
-	output := mat.NewDense(s, s, nil)
-
-	expandedInput := mat.NewDense(inputSize+2, inputSize+2, nil)
-	expandedInput.Zero()
-
-	// copy data from input taking Padding into account
-	for i := 0; i < inputSize; i++ {
-		for j := 0; j < inputSize; j++ {
-			expandedInput.Set(i+1, j+1, input.At(i, j))
+	kernelSize, kernelCols := kernel.Dims()
+	if kernelSize != kernelCols {
+		return *mat.NewDense(1, 1, nil), errors.New("kernel has to be square")
+	}
+	return Correlate2d(input, kernel, 1, kernelSize-1)
+}
+
+// surrounds input with padding number of zero rows and columns from every side
+func Pad2d(input mat.Dense, padding int) mat.Dense {
+	if padding == 0 {
+		return input
+	}
+
+	rows, cols := input.Dims()
+	output := mat.NewDense(rows+2*padding, cols+2*padding, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			output.Set(i+padding, j+padding, input.At(i, j))
 		}
 	}
 
-	for i := 0; i < s; i++ {
-		for j := 0; j < s; j++ {
-			value, err := Correlate2dOps(expandedInput.Slice(i, i+kernelSize, j, j+kernelSize), &kernel)
-			if err != nil {
-				return *mat.NewDense(1, 1, nil), err
-			}
-			output.Set(i, j, value)
+	return *output
+}
+
+// inserts stride - 1 zero rows and columns between input values
+// used in backward pass of strided cross-correlation
+func Dilate2d(input mat.Dense, stride int) mat.Dense {
+	if stride == 1 {
+		return input
+	}
+
+	rows, cols := input.Dims()
+	output := mat.NewDense((rows-1)*stride+1, (cols-1)*stride+1, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			output.Set(i*stride, j*stride, input.At(i, j))
 		}
 	}
 
-	return *output, nil
+	return *output
+}
+
+// rotates matrix by 180 degrees. Full cross-correlation with rotated kernel is a full convolution
+func Rotate180(input mat.Dense) mat.Dense {
+	rows, cols := input.Dims()
+	output := mat.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			output.Set(rows-1-i, cols-1-j, input.At(i, j))
+		}
+	}
+
+	return *output
 }
@@ -74,3 +74,34 @@ func compare(l []float64, r []float64) bool {
 
 	return true
 }
+
+func TestCorrelate2DStrideAndPadding(t *testing.T) {
+	input := *mat.NewDense(3, 3, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8})
+	kernel := *mat.NewDense(2, 2, []float64{0, 1, 2, 3})
+
+	result, _ := ops.Correlate2d(input, kernel, 2, 1)
+
+	if !compare(result.RawMatrix().Data, []float64{0, 8, 21, 43}) {
+		t.Fatalf("Unexpected result: %v", result.RawMatrix().Data)
+	}
+}
+
+func TestDilate2D(t *testing.T) {
+	input := *mat.NewDense(2, 2, []float64{1, 2, 3, 4})
+
+	result := ops.Dilate2d(input, 2)
+
+	if !compare(result.RawMatrix().Data, []float64{1, 0, 2, 0, 0, 0, 3, 0, 4}) {
+		t.Fatalf("Unexpected result: %v", result.RawMatrix().Data)
+	}
+}
+
+func TestRotate180(t *testing.T) {
+	input := *mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, 6})
+
+	result := ops.Rotate180(input)
+
+	if !compare(result.RawMatrix().Data, []float64{6, 5, 4, 3, 2, 1}) {
+		t.Fatalf("Unexpected result: %v", result.RawMatrix().Data)
+	}
+}
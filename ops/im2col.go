@@ -0,0 +1,78 @@
+package ops
+
+import "gonum.org/v1/gonum/mat"
+
+// Im2Col unrolls every patch covered by a kernel into a column, so cross-correlation becomes matrix multiplication
+// input - raw data of one sample where channels are stored one after another: channels * height * width
+// result has channels * kernelSize * kernelSize rows and outputHeight * outputWidth columns
+// row index is channel * kernelSize * kernelSize + kernelRow * kernelSize + kernelCol, same as flatten kernels
+func Im2Col(input []float64, channels, height, width, kernelSize, stride, padding int) *mat.Dense {
+	outputHeight := (height+2*padding-kernelSize)/stride + 1
+	outputWidth := (width+2*padding-kernelSize)/stride + 1
+	outputSize := outputHeight * outputWidth
+
+	cols := mat.NewDense(channels*kernelSize*kernelSize, outputSize, nil)
+	data := cols.RawMatrix().Data
+
+	for c := 0; c < channels; c++ {
+		channel := input[c*height*width : (c+1)*height*width]
+		for ki := 0; ki < kernelSize; ki++ {
+			for kj := 0; kj < kernelSize; kj++ {
+				row := data[((c*kernelSize+ki)*kernelSize+kj)*outputSize:]
+				for i := 0; i < outputHeight; i++ {
+					inputI := i*stride + ki - padding
+					if inputI < 0 || inputI >= height {
+						// zero padding
+						continue
+					}
+					for j := 0; j < outputWidth; j++ {
+						inputJ := j*stride + kj - padding
+						if inputJ < 0 || inputJ >= width {
+							continue
+						}
+						row[i*outputWidth+j] = channel[inputI*width+inputJ]
+					}
+				}
+			}
+		}
+	}
+
+	return cols
+}
+
+// Col2Im is reverse operation to Im2Col
+// values from overlapping patches are summed, which is exactly what backward pass needs
+// result is raw data of one sample: channels * height * width
+func Col2Im(cols *mat.Dense, channels, height, width, kernelSize, stride, padding int) []float64 {
+	outputHeight := (height+2*padding-kernelSize)/stride + 1
+	outputWidth := (width+2*padding-kernelSize)/stride + 1
+	outputSize := outputHeight * outputWidth
+
+	result := make([]float64, channels*height*width)
+	rawCols := cols.RawMatrix()
+
+	for c := 0; c < channels; c++ {
+		channel := result[c*height*width : (c+1)*height*width]
+		for ki := 0; ki < kernelSize; ki++ {
+			for kj := 0; kj < kernelSize; kj++ {
+				rowIndex := (c*kernelSize+ki)*kernelSize + kj
+				row := rawCols.Data[rowIndex*rawCols.Stride : rowIndex*rawCols.Stride+outputSize]
+				for i := 0; i < outputHeight; i++ {
+					inputI := i*stride + ki - padding
+					if inputI < 0 || inputI >= height {
+						continue
+					}
+					for j := 0; j < outputWidth; j++ {
+						inputJ := j*stride + kj - padding
+						if inputJ < 0 || inputJ >= width {
+							continue
+						}
+						channel[inputI*width+inputJ] += row[i*outputWidth+j]
+					}
+				}
+			}
+		}
+	}
+
+	return result
+}
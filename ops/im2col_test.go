@@ -0,0 +1,84 @@
+package ops_test
+
+import (
+	"main/ops"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestIm2Col(t *testing.T) {
+	input := []float64{0, 1, 2, 3, 4, 5, 6, 7, 8}
+
+	cols := ops.Im2Col(input, 1, 3, 3, 2, 1, 0)
+
+	if r, c := cols.Dims(); r != 4 || c != 4 {
+		t.Fatalf("Incorrect dims: %v %v", r, c)
+	}
+	if !compare(cols.RawMatrix().Data, []float64{0, 1, 3, 4, 1, 2, 4, 5, 3, 4, 6, 7, 4, 5, 7, 8}) {
+		t.Fatalf("Unexpected result: %v", cols.RawMatrix().Data)
+	}
+}
+
+func TestIm2ColMatchesCorrelate2D(t *testing.T) {
+	input := make([]float64, 7*6)
+	for i := range input {
+		input[i] = math.Sin(float64(i))
+	}
+	kernel := mat.NewDense(3, 3, []float64{1, -2, 0.5, 0.3, 2, -1, 0, 1, 4})
+
+	for _, stride := range []int{1, 2, 3} {
+		for _, padding := range []int{0, 1, 2} {
+			expected, err := ops.Correlate2d(*mat.NewDense(7, 6, input), *kernel, stride, padding)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cols := ops.Im2Col(input, 1, 7, 6, 3, stride, padding)
+			_, outputSize := cols.Dims()
+			result := mat.NewDense(1, outputSize, nil)
+			result.Mul(mat.NewDense(1, 9, kernel.RawMatrix().Data), cols)
+
+			if !isClose(expected.RawMatrix().Data, result.RawMatrix().Data) {
+				t.Fatalf("Mismatch for stride: %v padding: %v", stride, padding)
+			}
+		}
+	}
+}
+
+// Col2Im has to be adjoint to Im2Col: <Im2Col(x), y> == <x, Col2Im(y)>
+func TestCol2Im(t *testing.T) {
+	x := make([]float64, 2*5*5)
+	for i := range x {
+		x[i] = math.Cos(float64(i))
+	}
+	cols := ops.Im2Col(x, 2, 5, 5, 3, 2, 1)
+	r, c := cols.Dims()
+	y := mat.NewDense(r, c, nil)
+	for i := range y.RawMatrix().Data {
+		y.RawMatrix().Data[i] = math.Sin(float64(i) * 0.7)
+	}
+
+	lhs := mat.Dot(mat.NewVecDense(r*c, cols.RawMatrix().Data), mat.NewVecDense(r*c, y.RawMatrix().Data))
+	image := ops.Col2Im(y, 2, 5, 5, 3, 2, 1)
+	rhs := mat.Dot(mat.NewVecDense(len(x), x), mat.NewVecDense(len(image), image))
+
+	if math.Abs(lhs-rhs) > 1e-9 {
+		t.Fatalf("Col2Im is not adjoint to Im2Col: %v != %v", lhs, rhs)
+	}
+}
+
+func isClose(l []float64, r []float64) bool {
+	if len(l) != len(r) {
+		return false
+	}
+
+	for i := 0; i < len(l); i++ {
+		if math.Abs(l[i]-r[i]) > 1e-9 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -60,7 +60,7 @@ func (loss *OptimizedCategoricalCrossentropyLoss) GetDInputs() *mat.Dense {
 	return nil
 }
 
-func (loss *OptimizedCategoricalCrossentropyLoss) SetLayers(layers []*layer.DenseLayer) {
+func (loss *OptimizedCategoricalCrossentropyLoss) SetLayers(layers []layer.TrainableLayer) {
 	loss.loss.SetLayers(layers)
 }
 
@@ -89,12 +89,18 @@ func MakeOptimizedCategorialCrossentropy() (OptimizedSoftmaxActivation, Optimize
 	l := OptimizedCategoricalCrossentropyLoss{}
 	l.loss = loss.CategoricalCrossentropyLoss{}
 
-	a.backwardImplementation = &ActivationSoftmaxLossCategorialCrossentropy{}
-	l.backwardImplementation = a.backwardImplementation
+	LinkOptimizedCategorialCrossentropy(&a, &l)
 
 	return a, l
 }
 
+// makes activation and loss share backward implementation
+// used when the pair is created separately, e.g. during model loading
+func LinkOptimizedCategorialCrossentropy(a *OptimizedSoftmaxActivation, l *OptimizedCategoricalCrossentropyLoss) {
+	a.backwardImplementation = &ActivationSoftmaxLossCategorialCrossentropy{}
+	l.backwardImplementation = a.backwardImplementation
+}
+
 // Optimized backward implementation
 
 type ActivationSoftmaxLossCategorialCrossentropy struct {
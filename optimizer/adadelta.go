@@ -0,0 +1,56 @@
+package optimizer
+
+import (
+	"main/layer"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// OptimizerAdadelta scales updates by the ratio of running averages of squared updates and squared gradients
+// learning rate 1 is the original algorithm
+type OptimizerAdadelta struct {
+	BaseOptimizer
+	Epsilon float64 `json:"epsilon"`
+	Rho     float64 `json:"rho"`
+}
+
+func NewAdadelta() OptimizerAdadelta {
+	return OptimizerAdadelta{
+		BaseOptimizer: BaseOptimizer{
+			CurrentLearningRate: 1.0,
+			LearningRate:        1.0,
+		},
+		Epsilon: 1e-6,
+		Rho:     0.9,
+	}
+}
+
+func (a *OptimizerAdadelta) Name() string {
+	return "Adadelta Optimizer"
+}
+
+func (optimizer *OptimizerAdadelta) UpdateParams(layer layer.TrainableLayer) {
+	for _, parameter := range layer.Parameters() {
+		optimizer.update(parameter.Values, parameter.Gradients, parameter.Slot(cacheSlot), parameter.Slot(deltasSlot))
+	}
+}
+
+func (optimizer *OptimizerAdadelta) update(values, gradients, cache, deltas *mat.Dense) {
+	cache.Apply(func(i, j int, v float64) float64 {
+		return optimizer.Rho*v + (1-optimizer.Rho)*math.Pow(gradients.At(i, j), 2)
+	}, cache)
+
+	updates := mat.DenseCopyOf(values)
+	updates.Apply(func(i, j int, v float64) float64 {
+		return math.Sqrt(deltas.At(i, j)+optimizer.Epsilon) / math.Sqrt(cache.At(i, j)+optimizer.Epsilon) * gradients.At(i, j)
+	}, updates)
+
+	deltas.Apply(func(i, j int, v float64) float64 {
+		return optimizer.Rho*v + (1-optimizer.Rho)*math.Pow(updates.At(i, j), 2)
+	}, deltas)
+
+	values.Apply(func(i, j int, v float64) float64 {
+		return v - optimizer.CurrentLearningRate*updates.At(i, j)
+	}, values)
+}
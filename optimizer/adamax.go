@@ -0,0 +1,53 @@
+package optimizer
+
+import (
+	"main/layer"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// OptimizerAdaMax is Adam variant scaling updates by the infinity norm (max) of past gradients
+type OptimizerAdaMax struct {
+	BaseOptimizer
+	Epsilon float64 `json:"epsilon"`
+	Beta1   float64 `json:"beta1"`
+	Beta2   float64 `json:"beta2"`
+}
+
+func NewAdaMax() OptimizerAdaMax {
+	return OptimizerAdaMax{
+		BaseOptimizer: BaseOptimizer{
+			CurrentLearningRate: 0.002,
+			LearningRate:        0.002,
+		},
+		Epsilon: 1e-7,
+		Beta1:   0.9,
+		Beta2:   0.999,
+	}
+}
+
+func (a *OptimizerAdaMax) Name() string {
+	return "AdaMax Optimizer"
+}
+
+// cache slot keeps exponentially weighted infinity norm
+func (optimizer *OptimizerAdaMax) UpdateParams(layer layer.TrainableLayer) {
+	for _, parameter := range layer.Parameters() {
+		optimizer.update(parameter.Values, parameter.Gradients, parameter.Slot(momentumsSlot), parameter.Slot(cacheSlot))
+	}
+}
+
+func (optimizer *OptimizerAdaMax) update(values, gradients, momentums, norms *mat.Dense) {
+	momentums.Apply(func(i, j int, v float64) float64 {
+		return optimizer.Beta1*v + (1-optimizer.Beta1)*gradients.At(i, j)
+	}, momentums)
+	norms.Apply(func(i, j int, v float64) float64 {
+		return math.Max(optimizer.Beta2*v, math.Abs(gradients.At(i, j)))
+	}, norms)
+
+	learningRate := optimizer.CurrentLearningRate / (1 - math.Pow(optimizer.Beta1, float64(optimizer.Iterations)+1))
+	values.Apply(func(i, j int, v float64) float64 {
+		return v - learningRate*momentums.At(i, j)/(norms.At(i, j)+optimizer.Epsilon)
+	}, values)
+}
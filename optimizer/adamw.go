@@ -0,0 +1,62 @@
+package optimizer
+
+import (
+	"main/layer"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// OptimizerAdamW is Adam with weight decay applied directly to values instead of adding it to gradients
+type OptimizerAdamW struct {
+	BaseOptimizer
+	Epsilon     float64 `json:"epsilon"`
+	Beta1       float64 `json:"beta1"`
+	Beta2       float64 `json:"beta2"`
+	WeightDecay float64 `json:"weightDecay"`
+}
+
+func NewAdamW() OptimizerAdamW {
+	return OptimizerAdamW{
+		BaseOptimizer: BaseOptimizer{
+			CurrentLearningRate: 0.001,
+			LearningRate:        0.001,
+		},
+		Epsilon:     1e-7,
+		Beta1:       0.9,
+		Beta2:       0.999,
+		WeightDecay: 0.01,
+	}
+}
+
+func (a *OptimizerAdamW) Name() string {
+	return "AdamW Optimizer"
+}
+
+func (optimizer *OptimizerAdamW) UpdateParams(layer layer.TrainableLayer) {
+	for _, parameter := range layer.Parameters() {
+		optimizer.update(parameter.Values, parameter.Gradients, parameter.Slot(momentumsSlot), parameter.Slot(cacheSlot))
+	}
+}
+
+func (optimizer *OptimizerAdamW) update(values, gradients, momentums, cache *mat.Dense) {
+	// decoupled weight decay
+	values.Scale(1-optimizer.CurrentLearningRate*optimizer.WeightDecay, values)
+
+	momentums.Apply(func(i, j int, v float64) float64 {
+		return optimizer.Beta1*v + (1-optimizer.Beta1)*gradients.At(i, j)
+	}, momentums)
+	cache.Apply(func(i, j int, v float64) float64 {
+		return optimizer.Beta2*v + (1-optimizer.Beta2)*math.Pow(gradients.At(i, j), 2)
+	}, cache)
+
+	step := float64(optimizer.Iterations) + 1
+	momentumsCorrection := 1 - math.Pow(optimizer.Beta1, step)
+	cacheCorrection := 1 - math.Pow(optimizer.Beta2, step)
+
+	values.Apply(func(i, j int, v float64) float64 {
+		momentum := momentums.At(i, j) / momentumsCorrection
+		cache := cache.At(i, j) / cacheCorrection
+		return v - optimizer.CurrentLearningRate*momentum/(math.Sqrt(cache)+optimizer.Epsilon)
+	}, values)
+}
@@ -1,11 +1,68 @@
 package optimizer
 
-import "main/layer"
+import (
+	"main/layer"
+	"main/scheduler"
+)
+
+// names of optimizer state slots stored within layer.Parameter
+const (
+	momentumsSlot = "momentums"
+	cacheSlot     = "cache"
+	// running average of squared updates of Adadelta
+	deltasSlot = "deltas"
+)
 
 type OptimizerInterface interface {
 	Name() string
 	GetCurrentLearningRate() float64
 	PreUpdate()
-	UpdateParams(layer *layer.DenseLayer)
+	UpdateParams(layer layer.TrainableLayer)
 	PostUpdate()
 }
+
+// ScheduledOptimizer takes learning rate of every iteration from a scheduler
+// all built-in optimizers implement it through BaseOptimizer
+type ScheduledOptimizer interface {
+	OptimizerInterface
+	GetScheduler() scheduler.SchedulerInterface
+	SetScheduler(scheduler scheduler.SchedulerInterface)
+}
+
+// BaseOptimizer keeps learning rate and iterations shared by all optimizers
+type BaseOptimizer struct {
+	CurrentLearningRate float64 `json:"currentLearningRate"`
+	LearningRate        float64 `json:"learningRate"`
+	// inverse-time decay used when Scheduler is nil
+	Decay      float64 `json:"decay"`
+	Iterations int     `json:"iterations"`
+
+	// stored by ModelDataProvider separately from the optimizer
+	Scheduler scheduler.SchedulerInterface `json:"-"`
+}
+
+// updates CurrentLearningRate for the current iteration
+func (optimizer *BaseOptimizer) PreUpdate() {
+	if optimizer.Scheduler != nil {
+		optimizer.CurrentLearningRate = optimizer.Scheduler.LearningRate(optimizer.LearningRate, optimizer.Iterations)
+	} else if optimizer.Decay > 0.0 {
+		decay := scheduler.InverseTimeDecay{Decay: optimizer.Decay}
+		optimizer.CurrentLearningRate = decay.LearningRate(optimizer.LearningRate, optimizer.Iterations)
+	}
+}
+
+func (optimizer *BaseOptimizer) PostUpdate() {
+	optimizer.Iterations += 1
+}
+
+func (optimizer *BaseOptimizer) GetCurrentLearningRate() float64 {
+	return optimizer.CurrentLearningRate
+}
+
+func (optimizer *BaseOptimizer) GetScheduler() scheduler.SchedulerInterface {
+	return optimizer.Scheduler
+}
+
+func (optimizer *BaseOptimizer) SetScheduler(scheduler scheduler.SchedulerInterface) {
+	optimizer.Scheduler = scheduler
+}
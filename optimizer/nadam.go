@@ -0,0 +1,59 @@
+package optimizer
+
+import (
+	"main/layer"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// OptimizerNadam is Adam with Nesterov momentum: the update looks one momentum step ahead
+type OptimizerNadam struct {
+	BaseOptimizer
+	Epsilon float64 `json:"epsilon"`
+	Beta1   float64 `json:"beta1"`
+	Beta2   float64 `json:"beta2"`
+}
+
+func NewNadam() OptimizerNadam {
+	return OptimizerNadam{
+		BaseOptimizer: BaseOptimizer{
+			CurrentLearningRate: 0.002,
+			LearningRate:        0.002,
+		},
+		Epsilon: 1e-7,
+		Beta1:   0.9,
+		Beta2:   0.999,
+	}
+}
+
+func (a *OptimizerNadam) Name() string {
+	return "Nadam Optimizer"
+}
+
+func (optimizer *OptimizerNadam) UpdateParams(layer layer.TrainableLayer) {
+	for _, parameter := range layer.Parameters() {
+		optimizer.update(parameter.Values, parameter.Gradients, parameter.Slot(momentumsSlot), parameter.Slot(cacheSlot))
+	}
+}
+
+func (optimizer *OptimizerNadam) update(values, gradients, momentums, cache *mat.Dense) {
+	momentums.Apply(func(i, j int, v float64) float64 {
+		return optimizer.Beta1*v + (1-optimizer.Beta1)*gradients.At(i, j)
+	}, momentums)
+	cache.Apply(func(i, j int, v float64) float64 {
+		return optimizer.Beta2*v + (1-optimizer.Beta2)*math.Pow(gradients.At(i, j), 2)
+	}, cache)
+
+	step := float64(optimizer.Iterations) + 1
+	// corrected momentum of the next step mixed with corrected current gradient
+	nextCorrection := 1 - math.Pow(optimizer.Beta1, step+1)
+	gradientCorrection := 1 - math.Pow(optimizer.Beta1, step)
+	cacheCorrection := 1 - math.Pow(optimizer.Beta2, step)
+
+	values.Apply(func(i, j int, v float64) float64 {
+		momentum := optimizer.Beta1*momentums.At(i, j)/nextCorrection + (1-optimizer.Beta1)*gradients.At(i, j)/gradientCorrection
+		cache := cache.At(i, j) / cacheCorrection
+		return v - optimizer.CurrentLearningRate*momentum/(math.Sqrt(cache)+optimizer.Epsilon)
+	}, values)
+}
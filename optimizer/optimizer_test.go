@@ -0,0 +1,65 @@
+package optimizer_test
+
+import (
+	"main/layer"
+	"main/optimizer"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// layer with one parameter of two values; DropoutLayer provides the rest of layer.LayerInterface
+type parameterLayer struct {
+	layer.DropoutLayer
+	values    *mat.Dense
+	gradients *mat.Dense
+	state     layer.ParameterState
+}
+
+func (l *parameterLayer) Parameters() []layer.Parameter {
+	return []layer.Parameter{{Name: "values", Values: l.values, Gradients: l.gradients, State: l.state}}
+}
+
+// runs three steps from values (1, -2) with fixed gradients
+func runSteps(o optimizer.OptimizerInterface) []float64 {
+	l := parameterLayer{values: mat.NewDense(1, 2, []float64{1, -2}), state: layer.ParameterState{}}
+	gradients := [][]float64{{0.5, 0.1}, {-0.3, 0.1}, {0.2, -0.4}}
+	for _, g := range gradients {
+		l.gradients = mat.NewDense(1, 2, g)
+		o.PreUpdate()
+		o.UpdateParams(&l)
+		o.PostUpdate()
+	}
+	return l.values.RawMatrix().Data
+}
+
+func TestOptimizerReferenceValues(t *testing.T) {
+	adamW := optimizer.NewAdamW()
+	adamW.LearningRate, adamW.CurrentLearningRate, adamW.WeightDecay = 0.01, 0.01, 0.1
+	nadam := optimizer.NewNadam()
+	nadam.LearningRate, nadam.CurrentLearningRate = 0.01, 0.01
+	adaMax := optimizer.NewAdaMax()
+	adaMax.LearningRate, adaMax.CurrentLearningRate = 0.01, 0.01
+	adadelta := optimizer.NewAdadelta()
+	nesterov := optimizer.NewSGD(0.1, 0, 0.9)
+	nesterov.Nesterov = true
+
+	// computed step by step with the published update rules
+	cases := []struct {
+		optimizer optimizer.OptimizerInterface
+		expected  []float64
+	}{
+		{&adamW, []float64{0.981635650152, -2.010527653526}},
+		{&nadam, []float64{0.983346562464, -2.017841024882}},
+		{&adaMax, []float64{0.985942198117, -2.017887434403}},
+		{&adadelta, []float64{0.997480128699, -2.001240517028}},
+		{&nesterov, []float64{0.87135, -1.98549}},
+	}
+
+	for _, c := range cases {
+		if actual := runSteps(c.optimizer); !floats.EqualApprox(c.expected, actual, 1e-9) {
+			t.Errorf("%v: expected %v, got %v", c.optimizer.Name(), c.expected, actual)
+		}
+	}
+}
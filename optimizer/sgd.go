@@ -7,20 +7,20 @@ import (
 )
 
 type OptimizerSGD struct {
-	CurrentLearningRate float64 `json:"CurrentLearningRate"`
-	LearningRate        float64 `json:"LearningRate"`
-	Decay               float64 `json:"Decay"`
-	Momentum            float64 `json:"Momentum"`
-	Iterations          int     `json:"Iterations"`
+	BaseOptimizer
+	Momentum float64 `json:"Momentum"`
+	// Nesterov momentum applies gradient at the position reached by momentum
+	Nesterov bool `json:"Nesterov"`
 }
 
 func NewSGD(learningRate float64, decay float64, momentum float64) OptimizerSGD {
 	return OptimizerSGD{
-		CurrentLearningRate: learningRate,
-		LearningRate:        learningRate,
-		Decay:               decay,
-		Momentum:            momentum,
-		Iterations:          0,
+		BaseOptimizer: BaseOptimizer{
+			CurrentLearningRate: learningRate,
+			LearningRate:        learningRate,
+			Decay:               decay,
+		},
+		Momentum: momentum,
 	}
 }
 
@@ -28,52 +28,36 @@ func (a *OptimizerSGD) Name() string {
 	return "SGD Optimizer"
 }
 
-func (optimizer *OptimizerSGD) PreUpdate() {
-	if optimizer.Decay > 0.0 {
-		optimizer.CurrentLearningRate = optimizer.LearningRate * (1.0 / (1.0 + optimizer.Decay*float64(optimizer.Iterations)))
+func (optimizer *OptimizerSGD) UpdateParams(layer layer.TrainableLayer) {
+	for _, parameter := range layer.Parameters() {
+		var momentums *mat.Dense
+		if optimizer.Momentum > 0.0 {
+			momentums = parameter.Slot(momentumsSlot)
+		}
+		optimizer.update(parameter.Values, parameter.Gradients, momentums)
 	}
 }
 
-func (optimizer *OptimizerSGD) UpdateParams(layer *layer.DenseLayer) {
-	weightUpdates := mat.DenseCopyOf(&layer.Weights)
-	biasUpdates := mat.DenseCopyOf(&layer.Biases)
+// updates values in place; momentums are used only if Momentum is set
+func (optimizer *OptimizerSGD) update(values, gradients, momentums *mat.Dense) {
+	updates := mat.DenseCopyOf(values)
 
 	if optimizer.Momentum > 0.0 {
-		if layer.WeightMomentums == nil {
-			layer.WeightMomentums = mat.DenseCopyOf(&layer.Weights)
-			layer.WeightMomentums.Zero()
-			layer.BiasMomentums = mat.DenseCopyOf(&layer.Biases)
-			layer.BiasMomentums.Zero()
+		updates.Apply(func(i, j int, v float64) float64 {
+			return optimizer.Momentum*momentums.At(i, j) - optimizer.CurrentLearningRate*gradients.At(i, j)
+		}, updates)
+		momentums.Copy(updates)
+		if optimizer.Nesterov {
+			updates.Apply(func(i, j int, v float64) float64 {
+				return optimizer.Momentum*v - optimizer.CurrentLearningRate*gradients.At(i, j)
+			}, momentums)
 		}
-		// weights
-		weightUpdates.Apply(func(i, j int, v float64) float64 {
-			return optimizer.Momentum*layer.WeightMomentums.At(i, j) - optimizer.CurrentLearningRate*layer.DWeights.At(i, j)
-		}, weightUpdates)
-		layer.WeightMomentums = weightUpdates
-		// bises
-		biasUpdates.Apply(func(i, j int, v float64) float64 {
-			return optimizer.Momentum*layer.BiasMomentums.At(i, j) - optimizer.CurrentLearningRate*layer.DBiases.At(i, j)
-		}, biasUpdates)
-		layer.BiasMomentums = biasUpdates
 	} else {
 		// vanilla SGD
-		weightUpdates.Apply(func(i, j int, v float64) float64 {
-			return (-1) * optimizer.CurrentLearningRate * layer.DWeights.At(i, j)
-		}, weightUpdates)
-		biasUpdates.Apply(func(i, j int, v float64) float64 {
-			return (-1) * optimizer.CurrentLearningRate * layer.DBiases.At(i, j)
-		}, biasUpdates)
+		updates.Apply(func(i, j int, v float64) float64 {
+			return (-1) * optimizer.CurrentLearningRate * gradients.At(i, j)
+		}, updates)
 	}
 
-	// update weights an biases
-	layer.Weights.Add(&layer.Weights, weightUpdates)
-	layer.Biases.Add(&layer.Biases, biasUpdates)
-}
-
-func (optimizer *OptimizerSGD) PostUpdate() {
-	optimizer.Iterations += 1
-}
-
-func (optimizer *OptimizerSGD) GetCurrentLearningRate() float64 {
-	return optimizer.CurrentLearningRate
+	values.Add(values, updates)
 }
@@ -0,0 +1,68 @@
+package scaler
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ScalerInterface transforms every feature (column) using statistics collected by Fit
+type ScalerInterface interface {
+	Name() string
+	// collects statistics of every column of x
+	Fit(x *mat.Dense)
+	// returns scaled copy of x
+	Transform(x *mat.Dense) (*mat.Dense, error)
+	// returns copy of x in the original scale
+	InverseTransform(x *mat.Dense) (*mat.Dense, error)
+}
+
+func FitTransform(scaler ScalerInterface, x *mat.Dense) (*mat.Dense, error) {
+	scaler.Fit(x)
+	return scaler.Transform(x)
+}
+
+// applies (v - shift) / scale to every column
+func transform(x *mat.Dense, shift, scale []float64) (*mat.Dense, error) {
+	if err := checkColumns(x, shift); err != nil {
+		return nil, err
+	}
+	result := &mat.Dense{}
+	result.Apply(func(i, j int, v float64) float64 {
+		return (v - shift[j]) / scale[j]
+	}, x)
+	return result, nil
+}
+
+// applies v * scale + shift to every column
+func inverseTransform(x *mat.Dense, shift, scale []float64) (*mat.Dense, error) {
+	if err := checkColumns(x, shift); err != nil {
+		return nil, err
+	}
+	result := &mat.Dense{}
+	result.Apply(func(i, j int, v float64) float64 {
+		return v*scale[j] + shift[j]
+	}, x)
+	return result, nil
+}
+
+func checkColumns(x *mat.Dense, statistics []float64) error {
+	_, cols := x.Dims()
+	if cols != len(statistics) {
+		return fmt.Errorf("scaler is fitted for %v features, got %v", len(statistics), cols)
+	}
+	return nil
+}
+
+// constant columns are left unscaled instead of dividing by zero
+func nonZero(scale []float64) {
+	for i, v := range scale {
+		if v == 0 {
+			scale[i] = 1
+		}
+	}
+}
+
+func column(x *mat.Dense, j int) []float64 {
+	return mat.Col(nil, j, x)
+}
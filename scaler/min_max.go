@@ -0,0 +1,84 @@
+package scaler
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// MinMaxScaler scales every feature into range RangeMin...RangeMax
+// zero value of the range means 0...1
+type MinMaxScaler struct {
+	RangeMin float64 `json:"range_min"`
+	RangeMax float64 `json:"range_max"`
+
+	DataMin []float64 `json:"data_min"`
+	DataMax []float64 `json:"data_max"`
+}
+
+// creates scaler into 0...1 range
+func NewMinMaxScaler() MinMaxScaler {
+	return MinMaxScaler{RangeMin: 0, RangeMax: 1}
+}
+
+func (s *MinMaxScaler) Name() string {
+	return "Min Max Scaler"
+}
+
+func (s *MinMaxScaler) Fit(x *mat.Dense) {
+	_, cols := x.Dims()
+	s.DataMin = make([]float64, cols)
+	s.DataMax = make([]float64, cols)
+	for j := 0; j < cols; j++ {
+		values := column(x, j)
+		s.DataMin[j] = floats.Min(values)
+		s.DataMax[j] = floats.Max(values)
+	}
+}
+
+func (s *MinMaxScaler) Transform(x *mat.Dense) (*mat.Dense, error) {
+	shift, scale, err := s.statistics()
+	if err != nil {
+		return nil, err
+	}
+	return transform(x, shift, scale)
+}
+
+func (s *MinMaxScaler) InverseTransform(x *mat.Dense) (*mat.Dense, error) {
+	shift, scale, err := s.statistics()
+	if err != nil {
+		return nil, err
+	}
+	return inverseTransform(x, shift, scale)
+}
+
+func (s *MinMaxScaler) bounds() (float64, float64, error) {
+	if s.RangeMin == 0 && s.RangeMax == 0 {
+		return 0, 1, nil
+	}
+	if s.RangeMax <= s.RangeMin {
+		return 0, 0, fmt.Errorf("invalid range %v...%v", s.RangeMin, s.RangeMax)
+	}
+	return s.RangeMin, s.RangeMax, nil
+}
+
+// v' = (v - DataMin) / (DataMax - DataMin) * (RangeMax - RangeMin) + RangeMin
+// is the same as (v - shift) / scale
+func (s *MinMaxScaler) statistics() ([]float64, []float64, error) {
+	rangeMin, rangeMax, err := s.bounds()
+	if err != nil {
+		return nil, nil, err
+	}
+	shift := make([]float64, len(s.DataMin))
+	scale := make([]float64, len(s.DataMin))
+	for j := range scale {
+		scale[j] = s.DataMax[j] - s.DataMin[j]
+		if scale[j] == 0 {
+			scale[j] = 1
+		}
+		scale[j] /= rangeMax - rangeMin
+		shift[j] = s.DataMin[j] - rangeMin*scale[j]
+	}
+	return shift, scale, nil
+}
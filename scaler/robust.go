@@ -0,0 +1,50 @@
+package scaler
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// RobustScaler centers features by median and scales them by interquartile range, so outliers affect it less
+type RobustScaler struct {
+	Median []float64 `json:"median"`
+	// difference between 75th and 25th percentiles
+	Scale []float64 `json:"scale"`
+}
+
+func (s *RobustScaler) Name() string {
+	return "Robust Scaler"
+}
+
+func (s *RobustScaler) Fit(x *mat.Dense) {
+	_, cols := x.Dims()
+	s.Median = make([]float64, cols)
+	s.Scale = make([]float64, cols)
+	for j := 0; j < cols; j++ {
+		values := column(x, j)
+		sort.Float64s(values)
+		s.Median[j] = percentile(values, 0.5)
+		s.Scale[j] = percentile(values, 0.75) - percentile(values, 0.25)
+	}
+	nonZero(s.Scale)
+}
+
+func (s *RobustScaler) Transform(x *mat.Dense) (*mat.Dense, error) {
+	return transform(x, s.Median, s.Scale)
+}
+
+func (s *RobustScaler) InverseTransform(x *mat.Dense) (*mat.Dense, error) {
+	return inverseTransform(x, s.Median, s.Scale)
+}
+
+// linear interpolation between closest ranks of sorted values, the same as numpy.percentile
+func percentile(sorted []float64, p float64) float64 {
+	position := p * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (position-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,72 @@
+package scaler_test
+
+import (
+	"main/scaler"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestScalers(t *testing.T) {
+	// the second feature is constant
+	x := mat.NewDense(4, 2, []float64{
+		1, 5,
+		2, 5,
+		3, 5,
+		10, 5,
+	})
+
+	minMax := scaler.NewMinMaxScaler()
+	minMax.RangeMin = -1
+
+	cases := []struct {
+		scaler   scaler.ScalerInterface
+		expected []float64
+	}{
+		// mean 4, standard deviation sqrt(12.5)
+		{&scaler.StandardScaler{}, []float64{-0.848528137, 0, -0.565685425, 0, -0.282842712, 0, 1.697056275, 0}},
+		{&minMax, []float64{-1, -1, -7.0 / 9, -1, -5.0 / 9, -1, 1, -1}},
+		// median 2.5, interquartile range 4.75 - 1.75
+		{&scaler.RobustScaler{}, []float64{-0.5, 0, -1.0 / 6, 0, 1.0 / 6, 0, 2.5, 0}},
+	}
+
+	for _, c := range cases {
+		scaled, err := scaler.FitTransform(c.scaler, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !mat.EqualApprox(mat.NewDense(4, 2, c.expected), scaled, 1e-8) {
+			t.Errorf("%v: unexpected values:\n%v", c.scaler.Name(), mat.Formatted(scaled))
+		}
+		restored, err := c.scaler.InverseTransform(scaled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !mat.EqualApprox(x, restored, 1e-8) {
+			t.Errorf("%v: inverse transform doesn't restore values", c.scaler.Name())
+		}
+
+		if _, err := c.scaler.Transform(mat.NewDense(1, 3, nil)); err == nil {
+			t.Errorf("%v: missing error for wrong number of features", c.scaler.Name())
+		}
+	}
+}
+
+func TestMinMaxScalerRange(t *testing.T) {
+	x := mat.NewDense(3, 1, []float64{2, 4, 6})
+
+	// zero value scales into 0...1
+	zero := scaler.MinMaxScaler{}
+	scaled, err := scaler.FitTransform(&zero, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mat.Equal(mat.NewDense(3, 1, []float64{0, 0.5, 1}), scaled) {
+		t.Fatalf("Unexpected values:\n%v", mat.Formatted(scaled))
+	}
+
+	invalid := scaler.MinMaxScaler{RangeMin: 1, RangeMax: 1}
+	if _, err := scaler.FitTransform(&invalid, x); err == nil {
+		t.Fatal("Missing error for empty range")
+	}
+}
@@ -0,0 +1,39 @@
+package scaler
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// StandardScaler scales features to zero mean and unit variance
+type StandardScaler struct {
+	Mean []float64 `json:"mean"`
+	// population standard deviation of every feature
+	Scale []float64 `json:"scale"`
+}
+
+func (s *StandardScaler) Name() string {
+	return "Standard Scaler"
+}
+
+func (s *StandardScaler) Fit(x *mat.Dense) {
+	_, cols := x.Dims()
+	s.Mean = make([]float64, cols)
+	s.Scale = make([]float64, cols)
+	for j := 0; j < cols; j++ {
+		values := column(x, j)
+		s.Mean[j] = stat.Mean(values, nil)
+		s.Scale[j] = math.Sqrt(stat.MomentAbout(2, values, s.Mean[j], nil))
+	}
+	nonZero(s.Scale)
+}
+
+func (s *StandardScaler) Transform(x *mat.Dense) (*mat.Dense, error) {
+	return transform(x, s.Mean, s.Scale)
+}
+
+func (s *StandardScaler) InverseTransform(x *mat.Dense) (*mat.Dense, error) {
+	return inverseTransform(x, s.Mean, s.Scale)
+}
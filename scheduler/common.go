@@ -0,0 +1,30 @@
+package scheduler
+
+import "fmt"
+
+// SchedulerInterface defines learning rate of every training step (optimizer iteration)
+type SchedulerInterface interface {
+	Name() string
+	// returns learning rate of iteration; initial is LearningRate of the optimizer
+	LearningRate(initial float64, iteration int) float64
+}
+
+// MetricScheduler changes learning rate depending on a metric which should decrease, e.g. validation loss
+// Model.Train passes the metric after every epoch
+type MetricScheduler interface {
+	SchedulerInterface
+	Observe(metric float64)
+}
+
+// Validate returns error of schedulers with parameters which can't produce learning rates, e.g. zero period
+// schedulers check their parameters with Validate() error method; Model.Train and model loading reject invalid ones
+func Validate(s SchedulerInterface) error {
+	validated, ok := s.(interface{ Validate() error })
+	if !ok {
+		return nil
+	}
+	if err := validated.Validate(); err != nil {
+		return fmt.Errorf("%v: %w", s.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+)
+
+// CosineWarmRestarts anneals learning rate from the initial one to MinLearningRate by cosine
+// and restarts from the initial one after Period iterations; every next period is PeriodMult times longer
+type CosineWarmRestarts struct {
+	Period          int     `json:"period"`
+	PeriodMult      int     `json:"period_mult"`
+	MinLearningRate float64 `json:"min_learning_rate"`
+}
+
+func (s *CosineWarmRestarts) Name() string {
+	return "Cosine Annealing with Warm Restarts"
+}
+
+func (s *CosineWarmRestarts) Validate() error {
+	if s.Period < 1 {
+		return errors.New("period must be positive")
+	}
+	if s.PeriodMult < 0 {
+		return errors.New("period multiplier must not be negative")
+	}
+	return nil
+}
+
+func (s *CosineWarmRestarts) LearningRate(initial float64, iteration int) float64 {
+	// position within the current period
+	period := s.Period
+	for iteration >= period {
+		iteration -= period
+		period *= max(s.PeriodMult, 1)
+	}
+
+	progress := float64(iteration) / float64(period)
+	return s.MinLearningRate + (initial-s.MinLearningRate)*(1+math.Cos(math.Pi*progress))/2
+}
+
+// OneCycle increases learning rate from initial/DivFactor to the initial one during the first WarmupFraction of TotalSteps
+// and then anneals it by cosine to initial/DivFactor/FinalDivFactor; it stays there after TotalSteps
+type OneCycle struct {
+	TotalSteps     int     `json:"total_steps"`
+	WarmupFraction float64 `json:"warmup_fraction"`
+	DivFactor      float64 `json:"div_factor"`
+	FinalDivFactor float64 `json:"final_div_factor"`
+}
+
+// creates schedule with commonly used parameters
+func NewOneCycle(totalSteps int) OneCycle {
+	return OneCycle{
+		TotalSteps:     totalSteps,
+		WarmupFraction: 0.3,
+		DivFactor:      25,
+		FinalDivFactor: 1e4,
+	}
+}
+
+func (s *OneCycle) Name() string {
+	return "One Cycle"
+}
+
+func (s *OneCycle) Validate() error {
+	if s.TotalSteps < 1 {
+		return errors.New("total steps must be positive")
+	}
+	if s.WarmupFraction < 0 || s.WarmupFraction >= 1 {
+		return errors.New("warmup fraction must be in [0, 1)")
+	}
+	if s.DivFactor <= 0 || s.FinalDivFactor <= 0 {
+		return errors.New("div factors must be positive")
+	}
+	return nil
+}
+
+func (s *OneCycle) LearningRate(initial float64, iteration int) float64 {
+	start := initial / s.DivFactor
+	end := start / s.FinalDivFactor
+	warmup := s.WarmupFraction * float64(s.TotalSteps)
+	step := float64(min(iteration, s.TotalSteps))
+
+	if step < warmup {
+		return cosineBetween(start, initial, step/warmup)
+	}
+	return cosineBetween(initial, end, (step-warmup)/(float64(s.TotalSteps)-warmup))
+}
+
+// returns value between from and to; progress goes from 0 to 1
+func cosineBetween(from, to, progress float64) float64 {
+	return to + (from-to)*(1+math.Cos(math.Pi*progress))/2
+}
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+)
+
+// InverseTimeDecay is lr / (1 + Decay * iteration); optimizers use it for their Decay field
+type InverseTimeDecay struct {
+	Decay float64 `json:"decay"`
+}
+
+func (s *InverseTimeDecay) Name() string {
+	return "Inverse Time Decay"
+}
+
+func (s *InverseTimeDecay) Validate() error {
+	if s.Decay < 0 {
+		return errors.New("decay must not be negative")
+	}
+	return nil
+}
+
+func (s *InverseTimeDecay) LearningRate(initial float64, iteration int) float64 {
+	return initial * (1.0 / (1.0 + s.Decay*float64(iteration)))
+}
+
+// StepDecay multiplies learning rate by Gamma every StepSize iterations
+type StepDecay struct {
+	StepSize int     `json:"step_size"`
+	Gamma    float64 `json:"gamma"`
+}
+
+func (s *StepDecay) Name() string {
+	return "Step Decay"
+}
+
+func (s *StepDecay) Validate() error {
+	if s.StepSize < 1 {
+		return errors.New("step size must be positive")
+	}
+	if s.Gamma <= 0 {
+		return errors.New("gamma must be positive")
+	}
+	return nil
+}
+
+func (s *StepDecay) LearningRate(initial float64, iteration int) float64 {
+	return initial * math.Pow(s.Gamma, float64(iteration/s.StepSize))
+}
+
+// ExponentialDecay multiplies learning rate by Gamma every iteration
+type ExponentialDecay struct {
+	Gamma float64 `json:"gamma"`
+}
+
+func (s *ExponentialDecay) Name() string {
+	return "Exponential Decay"
+}
+
+func (s *ExponentialDecay) Validate() error {
+	if s.Gamma <= 0 {
+		return errors.New("gamma must be positive")
+	}
+	return nil
+}
+
+func (s *ExponentialDecay) LearningRate(initial float64, iteration int) float64 {
+	return initial * math.Pow(s.Gamma, float64(iteration))
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import "errors"
+
+// ReduceOnPlateau multiplies learning rate by Factor when the observed metric
+// hasn't improved by MinDelta for Patience epochs, but not below MinLearningRate
+type ReduceOnPlateau struct {
+	Factor          float64 `json:"factor"`
+	Patience        int     `json:"patience"`
+	MinDelta        float64 `json:"min_delta"`
+	MinLearningRate float64 `json:"min_learning_rate"`
+
+	// state is stored with the model, so resumed training continues with the same reductions
+	// zero Scale of a new scheduler means no reduction yet
+	Scale    float64 `json:"scale"`
+	Best     float64 `json:"best"`
+	Observed bool    `json:"observed"`
+	Wait     int     `json:"wait"`
+}
+
+func NewReduceOnPlateau(factor float64, patience int) ReduceOnPlateau {
+	return ReduceOnPlateau{Factor: factor, Patience: patience, Scale: 1}
+}
+
+func (s *ReduceOnPlateau) Name() string {
+	return "Reduce on Plateau"
+}
+
+func (s *ReduceOnPlateau) Validate() error {
+	if s.Factor <= 0 || s.Factor > 1 {
+		return errors.New("factor must be in (0, 1]")
+	}
+	if s.Patience < 0 {
+		return errors.New("patience must not be negative")
+	}
+	return nil
+}
+
+func (s *ReduceOnPlateau) LearningRate(initial float64, iteration int) float64 {
+	return max(initial*s.scale(), s.MinLearningRate)
+}
+
+func (s *ReduceOnPlateau) scale() float64 {
+	if s.Scale == 0 {
+		return 1
+	}
+	return s.Scale
+}
+
+func (s *ReduceOnPlateau) Observe(metric float64) {
+	if !s.Observed || metric < s.Best-s.MinDelta {
+		s.Best = metric
+		s.Observed = true
+		s.Wait = 0
+		return
+	}
+
+	s.Wait += 1
+	if s.Wait >= s.Patience {
+		s.Scale = s.scale() * s.Factor
+		s.Wait = 0
+	}
+}
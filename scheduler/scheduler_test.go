@@ -0,0 +1,81 @@
+package scheduler_test
+
+import (
+	"main/scheduler"
+	"math"
+	"testing"
+)
+
+func TestSchedulers(t *testing.T) {
+	oneCycle := scheduler.NewOneCycle(100)
+
+	cases := []struct {
+		scheduler scheduler.SchedulerInterface
+		// learning rate by iteration for initial learning rate 1
+		expected map[int]float64
+	}{
+		{&scheduler.InverseTimeDecay{Decay: 0.1}, map[int]float64{0: 1, 10: 0.5}},
+		{&scheduler.StepDecay{StepSize: 10, Gamma: 0.5}, map[int]float64{0: 1, 9: 1, 10: 0.5, 25: 0.25}},
+		{&scheduler.ExponentialDecay{Gamma: 0.9}, map[int]float64{0: 1, 2: 0.81}},
+		{&scheduler.CosineWarmRestarts{Period: 10, PeriodMult: 2, MinLearningRate: 0.1}, map[int]float64{0: 1, 5: 0.55, 10: 1, 20: 0.55, 30: 1}},
+		{&scheduler.LinearWarmup{WarmupSteps: 4}, map[int]float64{0: 0.25, 3: 1, 100: 1}},
+		{&scheduler.LinearWarmup{WarmupSteps: 4, After: &scheduler.StepDecay{StepSize: 1, Gamma: 0.5}}, map[int]float64{4: 1, 5: 0.5}},
+		{&oneCycle, map[int]float64{0: 0.04, 15: 0.52, 30: 1, 100: 4e-6, 200: 4e-6}},
+	}
+
+	for _, c := range cases {
+		for iteration, expected := range c.expected {
+			if actual := c.scheduler.LearningRate(1, iteration); math.Abs(actual-expected) > 1e-9 {
+				t.Errorf("%v: iteration %v: expected %v, got %v", c.scheduler.Name(), iteration, expected, actual)
+			}
+		}
+	}
+}
+
+func TestReduceOnPlateau(t *testing.T) {
+	s := scheduler.NewReduceOnPlateau(0.5, 2)
+	s.MinLearningRate = 0.2
+
+	metrics := []float64{1.0, 0.9, 0.95, 0.91, 0.92, 0.93}
+	expected := []float64{1, 1, 1, 0.5, 0.5, 0.25}
+	for i, metric := range metrics {
+		s.Observe(metric)
+		if actual := s.LearningRate(1, 0); actual != max(expected[i], s.MinLearningRate) {
+			t.Fatalf("after metric %v: expected %v, got %v", i, expected[i], actual)
+		}
+	}
+}
+
+func TestZeroValueSchedulers(t *testing.T) {
+	invalid := []scheduler.SchedulerInterface{
+		&scheduler.StepDecay{},
+		&scheduler.ExponentialDecay{},
+		&scheduler.CosineWarmRestarts{},
+		&scheduler.OneCycle{},
+		&scheduler.ReduceOnPlateau{},
+		&scheduler.LinearWarmup{WarmupSteps: 2, After: &scheduler.CosineWarmRestarts{}},
+	}
+	for _, s := range invalid {
+		if scheduler.Validate(s) == nil {
+			t.Errorf("%v: missing error for zero parameters", s.Name())
+		}
+	}
+
+	valid := []scheduler.SchedulerInterface{&scheduler.InverseTimeDecay{}, &scheduler.LinearWarmup{}}
+	for _, s := range valid {
+		if err := scheduler.Validate(s); err != nil {
+			t.Errorf("%v: unexpected error: %v", s.Name(), err)
+		}
+	}
+
+	// literal without NewReduceOnPlateau starts with the initial learning rate
+	plateau := scheduler.ReduceOnPlateau{Factor: 0.5, Patience: 0}
+	if actual := plateau.LearningRate(1, 0); actual != 1 {
+		t.Fatalf("expected learning rate 1, got %v", actual)
+	}
+	plateau.Observe(1)
+	plateau.Observe(1)
+	if actual := plateau.LearningRate(1, 0); actual != 0.5 {
+		t.Fatalf("expected learning rate 0.5, got %v", actual)
+	}
+}
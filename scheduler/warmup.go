@@ -0,0 +1,38 @@
+package scheduler
+
+import "errors"
+
+// LinearWarmup increases learning rate linearly during WarmupSteps iterations
+// then After continues with iterations counted from the end of the warmup; nil After keeps the initial learning rate
+type LinearWarmup struct {
+	WarmupSteps int
+	After       SchedulerInterface
+}
+
+func (s *LinearWarmup) Name() string {
+	return "Linear Warmup"
+}
+
+func (s *LinearWarmup) Validate() error {
+	if s.WarmupSteps < 0 {
+		return errors.New("warmup steps must not be negative")
+	}
+	return Validate(s.After)
+}
+
+func (s *LinearWarmup) LearningRate(initial float64, iteration int) float64 {
+	if iteration < s.WarmupSteps {
+		return initial * float64(iteration+1) / float64(s.WarmupSteps)
+	}
+	if s.After == nil {
+		return initial
+	}
+	return s.After.LearningRate(initial, iteration-s.WarmupSteps)
+}
+
+// receives metrics if After needs them
+func (s *LinearWarmup) Observe(metric float64) {
+	if after, ok := s.After.(MetricScheduler); ok {
+		after.Observe(metric)
+	}
+}